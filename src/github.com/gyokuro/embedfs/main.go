@@ -1,21 +1,32 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
 	"flag"
 	"fmt"
-	generator "github.com/gyokuro/embedfs/pkg/embedfs"
+	"github.com/gyokuro/embedfs/pkg/embedfs"
+	"github.com/gyokuro/embedfs/pkg/generator"
+	"github.com/gyokuro/embedfs/pkg/lint"
+	"go/build"
 	"io/ioutil"
 	"log"
+	"net/http"
 	"os"
 	"os/exec"
+	"os/signal"
 	"path/filepath"
 	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
 )
 
 import (
-	"bytes"
 	resources "github.com/gyokuro/embedfs/resources/embedfs"
-	"io"
 )
 
 var (
@@ -23,12 +34,53 @@ var (
 	createDestDir  = flag.Bool("createDestDir", true, "Creation destination directory if not exists.")
 	matchPattern   = flag.String("match", ".+\\.(js|css|html|png)$", "Regex to match target files.")
 	excludePattern = flag.String("exclude", ".+(\\.git).*", "Regex to exclude target files.")
-	byteSlice      = flag.Bool("byteSlice", true, "Represent binary data as byte slice.")
 	gofmt          = flag.Bool("gofmt", true, "Run gofmt on generated source.")
 	generate       = flag.Bool("generate", false, "True to really write actual files.")
+	overwrite      = flag.Bool("overwrite", true, "Overwrite existing generated source.")
+	dedup          = flag.Bool("dedup", false, "Store files of the same contents once, in destDir/embedfs_blobs.")
+	runtimePackage = flag.String("runtime", "", "Import path of the runtime for generated code, e.g. "+generator.RuntimePackage+
+		"; empty copies it into destDir.")
+	workers      = flag.Int("j", runtime.NumCPU(), "Number of files to translate in parallel.")
+	strip        = flag.Int("strip", 0, "Leading path components to drop from the paths of the source argument.")
+	rootDir      = flag.String("root", "", "Directory under destDir holding the root of the virtual tree, for files at the top of it.")
+	reportFormat = flag.String("report", "", "Print a report of what was generated to stdout: json or text.")
+
+	watch         = flag.Bool("watch", false, "Keep running, generating again whatever changes in the sources affect.")
+	watchInterval = flag.Duration("watchInterval", time.Second, "How often -watch polls the sources.")
+	debounce      = flag.Duration("debounce", 200*time.Millisecond, "How long -watch waits for changes to settle.")
+	watchCmd      = flag.String("watchCmd", "", "Shell command -watch runs after each successful generation, e.g. 'go build'.")
+
+	maxUncompressedK    = flag.Int64("maxUncompressedK", 5, "Max in kilobytes uncompressed.")
+	minCompressionRatio = flag.Float64("minCompressionRatio", 0.5, "Min compression ratio.")
+	codec               = flag.String("codec", "zlib", "Default codec: zlib, deflate, gzip, lzw or auto to keep the smallest.")
+	level               = flag.Int("level", -1, "Default compression level, -2 (huffman only) to 9 (best).")
+	compressionPolicy   = embedfs.DefaultCompressionPolicy()
+	sources             sourceMappings
+	budgets             generator.Budgets
 )
 
+func init() {
+	flag.Var(compressionPolicy, "compress",
+		"Per-file compression rule glob=never|always|auto[:codec[:level]]; repeatable, first match wins.")
+	flag.Var(&sources, "map",
+		"Source directory or archive and where its files go in the virtual tree, src=/virtual/path; repeatable.")
+	flag.Var(&budgets, "budget",
+		"Limit [warn:]total|file|dir|files[:glob]=size, e.g. total=100M or warn:file:*.png=200K; repeatable.")
+}
+
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "extract" {
+		extract(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "lint" {
+		lintPackages(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "bootstrap" {
+		bootstrap(os.Args[2:])
+		return
+	}
 	flag.Parse()
 
 	pwd, err := os.Getwd()
@@ -49,213 +101,381 @@ func main() {
 		panic(err)
 	}
 
-	destDirAbs, err := filepath.Abs(*destDir)
-	if err != nil {
-		fmt.Println("Not valid directory -- Cannot derive absolute path from destDir: ", *destDir)
-		panic(err)
-	}
-
-	// Get the import root for the packages that will be generated.
-	importRoot, err := generator.CheckGoPath(destDirAbs)
-	if err != nil {
-		fmt.Println("destDir ", *destDir, " not reachable in $GOPATH")
-		panic(err)
-	}
-	log.Println("Import root: ", importRoot)
-
-	dir := "."
 	switch flag.NArg() {
 	case 0:
-	case 1:
-		dir = flag.Arg(0)
-		dirStat, err := os.Lstat(dir)
-		switch {
-		case err != nil:
-			log.Fatalf("%s does not exist.", dir)
-		case !dirStat.IsDir():
-			log.Fatalf("%s is not a directory.", dir)
+		if len(sources) == 0 {
+			sources = append(sources, generator.Source{Path: "."})
 		}
+	case 1:
+		sources = append([]generator.Source{{Path: flag.Arg(0)}}, sources...)
 	default:
 		executable, err := exec.LookPath(os.Args[0])
 		if err == nil {
-			fmt.Fprintf(os.Stderr, "usage: %s [<dir>]\n", executable)
+			fmt.Fprintf(os.Stderr, "usage: %s [<dir>|<archive>]\n       %s extract [<source>] <destDir>\n       %s lint [<dir>...]\n",
+				executable, executable, executable)
 		} else {
-			fmt.Fprintf(os.Stderr, "usage: resourcefs [<dir>]\n")
+			fmt.Fprintf(os.Stderr, "usage: resourcefs [<dir>|<archive>]\n       resourcefs extract [<source>] <destDir>\n       resourcefs lint [<dir>...]\n")
 		}
 		os.Exit(2)
 	}
 
-	var match, exclude *regexp.Regexp = nil, nil
+	if *reportFormat != "" && *reportFormat != "json" && *reportFormat != "text" {
+		log.Fatalf("Bad -report: %s; want json or text", *reportFormat)
+	}
 
+	compressionPolicy.MaxUncompressed = *maxUncompressedK << 10
+	compressionPolicy.MinRatio = *minCompressionRatio
+	defaultRule, err := embedfs.ParseCompressionRule(fmt.Sprintf("*=auto:%s:%d", *codec, *level))
+	if err != nil {
+		log.Fatalf("Bad -codec or -level: %s", err)
+	}
+	compressionPolicy.Default = defaultRule
+
+	opts := generator.Options{
+		DestDir:     *destDir,
+		Sources:     sources,
+		Strip:       *strip,
+		Root:        *rootDir,
+		Compression: compressionPolicy,
+		Workers:     *workers,
+		Budgets:     &budgets,
+		Gofmt:       *gofmt,
+		Dedup:       *dedup,
+		KeepNewer:   !*overwrite,
+		DryRun:      !*generate,
+
+		RuntimePackage: *runtimePackage,
+		Logger:         log.New(os.Stderr, "", log.LstdFlags),
+	}
 	if len(*matchPattern) > 0 {
-		match, err = regexp.Compile(*matchPattern)
-		if err != nil {
-			panic(err)
+		if opts.Match, err = regexp.Compile(*matchPattern); err != nil {
+			log.Fatalf("Bad -match: %s", err)
 		}
 	}
 	if len(*excludePattern) > 0 {
-		exclude, err = regexp.Compile(*excludePattern)
-		if err != nil {
-			panic(err)
+		if opts.Exclude, err = regexp.Compile(*excludePattern); err != nil {
+			log.Fatalf("Bad -exclude: %s", err)
 		}
 	}
 
-	// Get all the target files -- keyed by the directory
-	files, err := getAllFiles(dir)
+	// generate the fs interface implementation, unless shared
+	if opts.RuntimePackage == "" {
+		if opts.Runtime, err = resources.ReadFile("fs.go"); err != nil {
+			panic(err)
+		}
+		if err := checkTemplate(opts.Runtime); err != nil {
+			log.Printf("Warning: %s; run %s bootstrap", err, os.Args[0])
+		}
+	}
 
-	filesByDirectory := make(map[string][]string)
-	for _, file := range files {
+	if *watch {
+		watchSources(opts)
+		return
+	}
 
-		selected := true
+	report, err := generator.Generate(context.Background(), opts)
+	printReport(report, err)
+	if err != nil {
+		log.Fatal(err)
+	}
+	written := "wrote"
+	if opts.DryRun {
+		written = "would write (run with -generate=true to write them)"
+	}
+	log.Printf("Embedded %d files, skipped %d, %s %d Go files.", len(report.Files), len(report.Skipped), written, len(report.GoFiles))
+	if report.Saved > 0 {
+		log.Printf("Shared contents saved %s.", generator.FormatSize(report.Saved))
+	}
+}
 
-		if match != nil && !match.MatchString(file) {
-			selected = false
+// Prints the report as asked by -report, and any conflicts and budget
+// violations.
+func printReport(report generator.Report, err error) {
+	switch *reportFormat {
+	case "json":
+		report.WriteJSON(os.Stdout)
+	case "text":
+		report.WriteText(os.Stdout)
+	}
+	if errors.Is(err, generator.ErrConflict) {
+		for _, c := range report.Conflicts {
+			log.Println("Conflict:", c)
 		}
-
-		if selected && exclude != nil && exclude.MatchString(file) {
-			selected = false
+	}
+	for _, v := range report.Budget {
+		kind := "Over budget:"
+		if v.Rule.Warn {
+			kind = "Warning:"
 		}
-
-		if selected {
-			log.Printf("Selected: %s/%s\n", filepath.Dir(file), filepath.Base(file))
-			dir := filepath.Dir(file)
-			base := filepath.Base(file)
-			if _, exists := filesByDirectory[dir]; exists {
-				filesByDirectory[dir] = append(filesByDirectory[dir], base)
-			} else {
-				filesByDirectory[dir] = []string{base}
+		log.Println(kind, v)
+		for _, o := range v.Offenders {
+			size := generator.FormatSize(o.Size)
+			if v.Rule.Kind == generator.BudgetCount {
+				size = strconv.FormatInt(o.Size, 10) + " files"
 			}
-		} else {
-			log.Println("Skipping", file)
+			log.Printf("    %8s  %s", size, o.Path)
 		}
 	}
+}
 
-	// 1. Create directories for all the keys in filesByDirectory
-	// 2. Generate the go file and place them in the directory
-	for dir, files := range filesByDirectory {
-		outDir := filepath.Join(*destDir, dir)
-		err = os.MkdirAll(outDir, 0777)
-		if err != nil {
-			log.Fatalf("Cannot create directory %s: %s", outDir, err)
-		}
-
-		packageName := generator.Sanitize(dir)
-		for _, file := range files {
-			srcFile := filepath.Join(dir, file)
-			u := generator.NewTranslationUnit(importRoot, packageName, srcFile, file, outDir, *byteSlice)
-			if *generate {
-				err = u.Translate()
-				if err != nil {
-					panic(err)
-				}
-
-				if *gofmt && u.Gofmt() != nil {
-					panic(err)
+// Generates, then again on every change to the sources until interrupted,
+// printing a line for each cycle and running -watchCmd after those that
+// succeed.
+func watchSources(opts generator.Options) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	opts.Logger = nil
+	n := 0
+	err := generator.Watch(ctx, opts, generator.WatchOptions{Interval: *watchInterval, Debounce: *debounce},
+		func(c generator.Cycle) error {
+			n++
+			printReport(c.Report, c.Err)
+			changed := ""
+			if n > 1 {
+				changed = fmt.Sprintf("%d changed, ", len(c.Changed))
+			}
+			if c.Err != nil {
+				log.Printf("Cycle %d: %sfailed after %s: %s", n, changed, c.Elapsed.Round(time.Millisecond), c.Err)
+				return nil
+			}
+			written := "written"
+			if opts.DryRun {
+				written = "to write (run with -generate=true to write them)"
+			}
+			log.Printf("Cycle %d: %s%d files embedded, %d Go files %s, %d removed in %s", n, changed,
+				len(c.Report.Files), len(c.Report.GoFiles), written, len(c.Report.Removed), c.Elapsed.Round(time.Millisecond))
+			if *watchCmd != "" {
+				cmd := exec.CommandContext(ctx, "sh", "-c", *watchCmd)
+				cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+				if err := cmd.Run(); err != nil && ctx.Err() == nil {
+					log.Printf("Cycle %d: %s: %s", n, *watchCmd, err)
 				}
-			} else {
-				log.Printf("Translation Unit: %s", u)
 			}
-		}
+			return nil
+		})
+	if err != nil && err != context.Canceled {
+		log.Fatal(err)
 	}
+}
 
-	// 3. Look at the directory hierachy and generate toc entries for each directory
-	dirSeen := make(map[string]bool)
-	dirHierarchy := make(map[string][]string)
-	for directory, _ := range filesByDirectory {
+// Reports the constant paths that the packages in the directories give to
+// generated packages but that aren't in their trees.
+func lintPackages(args []string) {
+	flags := flag.NewFlagSet("lint", flag.ExitOnError)
+	assets := flags.String("assets", "", "Import path of the generated package AssetPath arguments are relative to.")
+	templates := flags.String("templates", "", "Glob of template files whose AssetPath arguments to check; needs -assets.")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: %s lint [flags] [<dir>|<dir>/...]...\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
 
-		p := directory
-		if _, exists := dirHierarchy[p]; !exists {
-			dirHierarchy[p] = []string{}
+	opts := lint.Options{Assets: *assets}
+	if *templates != "" {
+		if *assets == "" {
+			log.Fatal("-templates needs -assets")
 		}
-		for {
-			parent := filepath.Dir(p)
-			child := filepath.Base(p)
+		var err error
+		if opts.Templates, err = filepath.Glob(*templates); err != nil {
+			log.Fatalf("Bad -templates: %s", err)
+		}
+	}
+	dirs := flags.Args()
+	if len(dirs) == 0 {
+		dirs = []string{"."}
+	}
+	problems, err := lint.Check(dirs, opts)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, p := range problems {
+		fmt.Println(p)
+	}
+	if len(problems) > 0 {
+		os.Exit(1)
+	}
+}
 
-			if parent == "." {
-				break
-			}
+// Writes out the files of a tree: the zip, tar, tar.gz or directory given as
+// source, or else the resources embedded in this program.
+func extract(args []string) {
+	flags := flag.NewFlagSet("extract", flag.ExitOnError)
+	var overwrite embedfs.OverwritePolicy
+	flags.Var(&overwrite, "overwrite", "What to do about existing files: never (fail), skip, newer or always.")
+	matchPattern := flags.String("match", "", "Regex to match files to extract.")
+	excludePattern := flags.String("exclude", "", "Regex to exclude files from extraction.")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: %s extract [flags] [<source>] <destDir>\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
 
-			if _, seen := dirSeen[p]; !seen {
-				if list, exists := dirHierarchy[parent]; exists {
-					dirHierarchy[parent] = append(list, child)
-				} else {
-					dirHierarchy[parent] = []string{child}
-				}
-				dirSeen[p] = true
-			}
-			p = parent
+	var fsys http.FileSystem
+	var err error
+	switch flags.NArg() {
+	case 1:
+		fsys = resources.Mount()
+	case 2:
+		tree, err := embedfs.ReadSource(flags.Arg(0))
+		if err == nil {
+			fsys, err = tree.Sub(".")
+		}
+		if err != nil {
+			log.Fatalf("Cannot read %s: %s", flags.Arg(0), err)
 		}
+	default:
+		flags.Usage()
+		os.Exit(2)
 	}
+	destDir := flags.Arg(flags.NArg() - 1)
 
-	for directory, children := range dirHierarchy {
-		toc := generator.NewDirToc(destDirAbs, importRoot, directory, children)
-		if *generate {
-			err = toc.Translate()
-			if err != nil {
-				panic(err)
-			}
-			if *gofmt && toc.Gofmt() != nil {
-				panic(err)
-			}
-		} else {
-			log.Printf("TOC: %s", toc)
+	var match, exclude *regexp.Regexp
+	if *matchPattern != "" {
+		if match, err = regexp.Compile(*matchPattern); err != nil {
+			log.Fatalf("Bad -match: %s", err)
 		}
 	}
-
-	// generate the fs interface implementation
-	fs_template, err := resources.Mount().Open("fs.go")
-	log.Println("Using template: ", fs_template, err)
-	if err != nil {
-		panic(err)
+	if *excludePattern != "" {
+		if exclude, err = regexp.Compile(*excludePattern); err != nil {
+			log.Fatalf("Bad -exclude: %s", err)
+		}
+	}
+	opts := &embedfs.ExtractOptions{Overwrite: overwrite}
+	if match != nil || exclude != nil {
+		opts.Filter = func(name string) bool {
+			return (match == nil || match.MatchString(name)) && (exclude == nil || !exclude.MatchString(name))
+		}
+	}
+	if err := embedfs.Extract(fsys, destDir, opts); err != nil {
+		log.Fatalf("Cannot extract to %s: %s", destDir, err)
 	}
+	log.Println("Extracted to", destDir)
+}
 
-	fsOutPath := filepath.Join(*destDir, "generated-fs.go")
-	buff := bytes.NewBufferString("")
-	io.Copy(buff, fs_template)
+// Regenerates resources/, the copy of pkg/embedfs/fs.go this program
+// carries, then checks the fixed point: that the program built from it
+// carries the current fs.go.
+func bootstrap(args []string) {
+	flags := flag.NewFlagSet("bootstrap", flag.ExitOnError)
+	check := flags.Bool("check", false, "Only check that this program carries the current fs.go.")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: %s bootstrap [-check]\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
 
-	if *generate {
-		err = ioutil.WriteFile(fsOutPath, buff.Bytes(), 0644)
+	pkg, err := build.Import(bootstrapPackage, "", build.FindOnly)
+	if err != nil {
+		log.Fatalf("Cannot find %s: %s", bootstrapPackage, err)
+	}
+	source, err := ioutil.ReadFile(filepath.Join(pkg.Dir, "pkg", "embedfs", "fs.go"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *check {
+		embedded, err := resources.ReadFile("fs.go")
 		if err != nil {
-			panic(err)
+			log.Fatal(err)
+		}
+		if !bytes.Equal(embedded, source) {
+			log.Fatalf("The embedded fs.go is not %s", filepath.Join(pkg.Dir, "pkg", "embedfs", "fs.go"))
+		}
+		if err := checkTemplate(embedded); err != nil {
+			log.Fatal(err)
 		}
-		log.Println("Generated fs.go in ", fsOutPath)
+		log.Println("The embedded fs.go is current.")
+		return
+	}
+
+	// 1. Record the digest of fs.go in the runtime
+	digest := fmt.Sprintf(digestTemplate, sha256.Sum256(source))
+	if err := ioutil.WriteFile(filepath.Join(pkg.Dir, "pkg", "embedfs", "digest.go"), []byte(digest), 0644); err != nil {
+		log.Fatal(err)
+	}
+
+	// 2. Regenerate resources/embedfs from pkg/embedfs/fs.go; run from pkg/ so
+	// that the generated code names it embedfs/fs.go wherever the repo is.
+	if err := os.Chdir(filepath.Join(pkg.Dir, "pkg")); err != nil {
+		log.Fatal(err)
+	}
+	_, err = generator.Generate(context.Background(), generator.Options{
+		DestDir:    "../resources",
+		ImportRoot: bootstrapPackage + "/resources",
+		Sources:    []generator.Source{{Path: "embedfs"}},
+		Match:      regexp.MustCompile(`/fs\.go$`),
+		Gofmt:      true,
+		Logger:     log.New(os.Stderr, "", log.LstdFlags),
+	})
+	if err != nil {
+		log.Fatalf("Cannot regenerate resources: %s", err)
 	}
 
+	// 3. Build the program again and have it check what it carries
+	dir, err := ioutil.TempDir("", "embedfs-bootstrap")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	executable := filepath.Join(dir, "embedfs")
+	for _, command := range []*exec.Cmd{
+		exec.Command("go", "build", "-o", executable, "main.go"),
+		exec.Command(executable, "bootstrap", "-check"),
+	} {
+		command.Dir, command.Stdout, command.Stderr = pkg.Dir, os.Stdout, os.Stderr
+		if err := command.Run(); err != nil {
+			log.Fatalf("%s: %s", strings.Join(command.Args, " "), err)
+		}
+	}
+	log.Printf("Bootstrapped: rebuild %s to carry the current fs.go.", os.Args[0])
 }
 
-func concat(a []string, b []string) []string {
-	c := make([]string, len(a)+len(b))
-	copy(c, a)
-	copy(c[len(a):], b)
-	return c
+const bootstrapPackage = "github.com/gyokuro/embedfs"
+
+const digestTemplate = `// AUTO-GENERATED BY embedfs bootstrap
+// DO NOT EDIT!!!
+package embedfs
+
+// SHA-256 of fs.go, the runtime copied into generated code, as of the last
+// ` + "`embedfs bootstrap`" + `.  The embedfs command carries a copy of fs.go and warns
+// when that copy has another digest.
+const TemplateDigest = "%x"
+`
+
+var formatVersion = regexp.MustCompile(`(?m)^const FormatVersion = (\d+)$`)
+
+// Checks that the copy of fs.go this program carries is the one the runtime
+// library was bootstrapped with, and reads the same format.
+func checkTemplate(template []byte) error {
+	if digest := fmt.Sprintf("%x", sha256.Sum256(template)); digest != embedfs.TemplateDigest {
+		return fmt.Errorf("the embedded fs.go has digest %s, but the runtime library was bootstrapped with %s",
+			digest, embedfs.TemplateDigest)
+	}
+	if m := formatVersion.FindSubmatch(template); m == nil || string(m[1]) != strconv.Itoa(embedfs.FormatVersion) {
+		return fmt.Errorf("the embedded fs.go is not for format version %d", embedfs.FormatVersion)
+	}
+	return nil
 }
 
-// Returns a list of files under the given directory
-func getAllFiles(path string) ([]string, error) {
-	var result = make([]string, 0)
-	stat, err := os.Lstat(path)
-	if err != nil {
-		log.Printf("Error stat %s: %s", path, err)
-		return result, err
+// Sources given with -map src=/virtual/path.
+type sourceMappings []generator.Source
+
+func (m *sourceMappings) String() string {
+	list := []string{}
+	for _, source := range *m {
+		list = append(list, source.Path+"="+source.Virtual)
 	}
+	return strings.Join(list, ",")
+}
 
-	switch {
-	case stat.Mode().IsRegular():
-		result = append(result, filepath.Clean(path))
-	case stat.Mode().IsDir():
-		// List the directory contents
-		files, err := ioutil.ReadDir(path)
-		if err != nil {
-			log.Printf("Error readdir %s: %s", path, err)
-			return result, err
-		}
-		for _, file := range files {
-			children, err := getAllFiles(filepath.Join(path, file.Name()))
-			if err != nil {
-				return result, err
-			}
-			result = concat(result, children)
-		}
+func (m *sourceMappings) Set(s string) error {
+	i := strings.LastIndex(s, "=")
+	if i <= 0 {
+		return fmt.Errorf("expected src=virtual, got %s", s)
+	}
+	virtual := s[i+1:]
+	if virtual == "" {
+		virtual = "/"
 	}
-	return result, err
+	*m = append(*m, generator.Source{Path: s[:i], Virtual: virtual})
+	return nil
 }
@@ -0,0 +1,307 @@
+package embedfs
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+)
+
+// Implemented by EmbedFile, whichever copy of the runtime it comes from.
+type storedFile interface {
+	Stored() (codec string, data []byte)
+}
+
+var _ storedFile = (*EmbedFile)(nil)
+
+// Serves directories of a file system as archives streamed to the client:
+// the request path names the directory, with ".zip" or ".tar.gz" appended
+// for the format, so that "/docs/guide.zip" is the zip of docs/guide and
+// "/.tar.gz" that of the root.  Other paths are not found.  Paths in the
+// archive are relative to the directory, and only those accepted by filter,
+// if not nil, are included:
+//
+//	http.Handle("/download/", http.StripPrefix("/download", embedfs.ArchiveServer(site.Mount(), nil)))
+func ArchiveServer(fsys http.FileSystem, filter func(name string) bool) http.Handler {
+	return &archiveServer{fsys: fsys, filter: filter}
+}
+
+type archiveServer struct {
+	fsys   http.FileSystem
+	filter func(name string) bool
+}
+
+var archiveFormats = []struct {
+	ext, contentType string
+	write            func(io.Writer, http.FileSystem, string, func(string) bool) error
+}{
+	{".zip", "application/zip", WriteZip},
+	{".tar.gz", "application/gzip", WriteTarGz},
+}
+
+func (s *archiveServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	for _, format := range archiveFormats {
+		if !strings.HasSuffix(r.URL.Path, format.ext) {
+			continue
+		}
+		dir := strings.TrimSuffix(r.URL.Path, format.ext)
+		stat, err := statName(s.fsys, dir)
+		if err != nil || !stat.IsDir() {
+			break
+		}
+		name := path.Base(dir)
+		if name == "/" || name == "." || name == "" {
+			name = "archive"
+		}
+		w.Header().Set("Content-Type", format.contentType)
+		w.Header().Set("Content-Disposition", contentDisposition(name+format.ext))
+		if r.Method == http.MethodHead {
+			return
+		}
+		// Buffered, so that the headers of the gzip and tar streams don't
+		// start the response before the first file is read.
+		started := &startedWriter{ResponseWriter: w}
+		buffered := bufio.NewWriterSize(started, 32<<10)
+		err = format.write(buffered, s.fsys, dir, s.filter)
+		if err == nil {
+			err = buffered.Flush()
+		}
+		if err != nil {
+			if !started.started {
+				w.Header().Del("Content-Disposition")
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			// Too late for an error status; cut the response short instead.
+			panic(http.ErrAbortHandler)
+		}
+		return
+	}
+	http.NotFound(w, r)
+}
+
+// The attachment header for a file name, quoted or encoded as it needs.
+func contentDisposition(filename string) string {
+	if disposition := mime.FormatMediaType("attachment", map[string]string{"filename": filename}); disposition != "" {
+		return disposition
+	}
+	return "attachment"
+}
+
+// Records whether the response has started, so that an error can still be
+// answered with a status until it has.
+type startedWriter struct {
+	http.ResponseWriter
+	started bool
+}
+
+func (w *startedWriter) WriteHeader(status int) {
+	w.started = true
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *startedWriter) Write(data []byte) (int, error) {
+	w.started = true
+	return w.ResponseWriter.Write(data)
+}
+
+// Calls fn for everything below dir, with paths relative to it.  The
+// filter, if not nil, decides on files; directories are only passed
+// without one.
+func walkArchive(fsys http.FileSystem, dir string, filter func(string) bool, fn func(name string, info os.FileInfo) error) error {
+	clean, err := cleanPath("open", dir)
+	if err != nil {
+		return err
+	}
+	return walkTree(fsys, clean, func(name string, info os.FileInfo) error {
+		if name == clean {
+			return nil
+		}
+		if clean != "." {
+			name = name[len(clean)+1:]
+		}
+		if filter != nil && (info.IsDir() || !filter(name)) {
+			return nil
+		}
+		return fn(name, info)
+	})
+}
+
+// Writes the directory as a zip archive.  Files stored as deflate, zlib or
+// gzip are written as they are stored, without compressing them again.
+func WriteZip(w io.Writer, fsys http.FileSystem, dir string, filter func(name string) bool) error {
+	archive := zip.NewWriter(w)
+	err := walkArchive(fsys, dir, filter, func(name string, info os.FileInfo) error {
+		header := &zip.FileHeader{Name: name, Modified: info.ModTime()}
+		header.SetMode(info.Mode())
+		if info.IsDir() {
+			header.Name += "/"
+			_, err := archive.CreateHeader(header)
+			return err
+		}
+		header.Method = zip.Deflate
+		header.UncompressedSize64 = uint64(info.Size())
+
+		if stored, ok := info.(storedFile); ok {
+			if deflated, crc, known := rawDeflate(stored.Stored()); deflated != nil {
+				if !known {
+					var err error
+					if crc, err = checksum(fsys, path.Join(dir, name)); err != nil {
+						return err
+					}
+				}
+				header.CRC32 = crc
+				header.CompressedSize64 = uint64(len(deflated))
+				setRawModTime(header)
+				out, err := archive.CreateRaw(header)
+				if err != nil {
+					return err
+				}
+				_, err = out.Write(deflated)
+				return err
+			}
+		}
+		out, err := archive.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+		return copyFile(out, fsys, path.Join(dir, name))
+	})
+	if err != nil {
+		return err
+	}
+	return archive.Close()
+}
+
+// Writes the directory as a gzipped tar archive.
+func WriteTarGz(w io.Writer, fsys http.FileSystem, dir string, filter func(name string) bool) error {
+	gz := gzip.NewWriter(w)
+	archive := tar.NewWriter(gz)
+	err := walkArchive(fsys, dir, filter, func(name string, info os.FileInfo) error {
+		header := &tar.Header{
+			Name:     name,
+			Mode:     int64(info.Mode().Perm()),
+			ModTime:  info.ModTime(),
+			Typeflag: tar.TypeReg,
+			Size:     info.Size(),
+		}
+		if info.IsDir() {
+			header.Name += "/"
+			header.Typeflag = tar.TypeDir
+			header.Size = 0
+			return archive.WriteHeader(header)
+		}
+		if err := archive.WriteHeader(header); err != nil {
+			return err
+		}
+		return copyFile(archive, fsys, path.Join(dir, name))
+	})
+	if err == nil {
+		err = archive.Close()
+	}
+	if err == nil {
+		err = gz.Close()
+	}
+	return err
+}
+
+// CreateRaw leaves the times to the caller: set them as CreateHeader would,
+// MS-DOS fields and the extended timestamp.
+func setRawModTime(header *zip.FileHeader) {
+	header.SetModTime(header.Modified)
+	var extra [9]byte
+	binary.LittleEndian.PutUint16(extra[0:], 0x5455) // extended timestamp
+	binary.LittleEndian.PutUint16(extra[2:], 5)
+	extra[4] = 1 // modification time only
+	binary.LittleEndian.PutUint32(extra[5:], uint32(header.Modified.Unix()))
+	header.Extra = append(header.Extra, extra[:]...)
+}
+
+func copyFile(w io.Writer, fsys http.FileSystem, name string) error {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(w, f)
+	return err
+}
+
+func checksum(fsys http.FileSystem, name string) (uint32, error) {
+	crc := crc32.NewIEEE()
+	if err := copyFile(crc, fsys, name); err != nil {
+		return 0, err
+	}
+	return crc.Sum32(), nil
+}
+
+var errNotDeflate = errors.New("not a deflate stream")
+
+// Finds the raw deflate stream inside data stored with the codec, and its
+// CRC-32 if the codec records it, as gzip does.  Returns nil if the codec
+// doesn't hold a deflate stream.
+func rawDeflate(codec string, data []byte) (deflated []byte, crc uint32, known bool) {
+	switch codec {
+	case CodecDeflate:
+		return data, 0, false
+	case CodecZlib:
+		// 2 byte header, no preset dictionary, Adler-32 trailer.
+		if len(data) < 6 || data[0]&0x0f != 8 || data[1]&0x20 != 0 {
+			return nil, 0, false
+		}
+		return data[2 : len(data)-4], 0, false
+	case CodecGzip:
+		start, err := gzipHeaderSize(data)
+		if err != nil || len(data) < start+8 {
+			return nil, 0, false
+		}
+		return data[start : len(data)-8], binary.LittleEndian.Uint32(data[len(data)-8:]), true
+	}
+	return nil, 0, false
+}
+
+// The size of a gzip member header (RFC 1952).
+func gzipHeaderSize(data []byte) (int, error) {
+	const (
+		flagHeaderCRC = 1 << 1
+		flagExtra     = 1 << 2
+		flagName      = 1 << 3
+		flagComment   = 1 << 4
+	)
+	if len(data) < 10 || data[0] != 0x1f || data[1] != 0x8b || data[2] != 8 {
+		return 0, errNotDeflate
+	}
+	flags, n := data[3], 10
+	if flags&flagExtra != 0 {
+		if len(data) < n+2 {
+			return 0, errNotDeflate
+		}
+		n += 2 + int(binary.LittleEndian.Uint16(data[n:]))
+	}
+	for _, flag := range []byte{flagName, flagComment} {
+		if flags&flag == 0 {
+			continue
+		}
+		end := n
+		for end < len(data) && data[end] != 0 {
+			end++
+		}
+		n = end + 1
+	}
+	if flags&flagHeaderCRC != 0 {
+		n += 2
+	}
+	if n > len(data) {
+		return 0, errNotDeflate
+	}
+	return n, nil
+}
@@ -0,0 +1,209 @@
+package embedfs
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// A tree with a file stored with each codec.
+func codecTree(t *testing.T) *EmbedDir {
+	content := []byte(strings.Repeat("all work and no play makes jack a dull boy\n", 200))
+	files := map[string][]byte{"empty/": nil}
+	policy := &CompressionPolicy{Default: CompressionRule{Pattern: "*", Mode: CompressNever}}
+	files["codec/none.txt"] = content
+	for _, codec := range autoCodecs {
+		files["codec/"+codec+".txt"] = content
+		policy.Rules = append(policy.Rules, CompressionRule{Pattern: codec + ".txt", Mode: CompressAlways, Codec: codec, Level: 9})
+	}
+	files["codec/index.html"] = []byte("<html></html>")
+	dir, err := FromMap(files, &BuildOptions{Compression: policy, ModTime: buildTime})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestWriteZip(t *testing.T) {
+	tree := codecTree(t)
+	var buff bytes.Buffer
+	if err := WriteZip(&buff, mountTree(t, tree), "/codec", nil); err != nil {
+		t.Fatal(err)
+	}
+	archive, err := zip.NewReader(bytes.NewReader(buff.Bytes()), int64(buff.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(archive.File) != 6 {
+		t.Errorf("%d entries", len(archive.File))
+	}
+	for _, f := range archive.File {
+		entry, exists := tree.lookup("codec/" + f.Name)
+		if !exists {
+			t.Errorf("unexpected entry %s", f.Name)
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := ioutil.ReadAll(rc) // checks the CRC-32 too
+		if err != nil {
+			t.Errorf("%s: %s", f.Name, err)
+		}
+		want, _ := entry.File.Bytes()
+		if !bytes.Equal(got, want) {
+			t.Errorf("%s: contents differ", f.Name)
+		}
+		if !f.Modified.Equal(buildTime) {
+			t.Errorf("%s has time %s", f.Name, f.Modified)
+		}
+
+		// Stored deflate data is passed through.
+		if deflated, _, _ := rawDeflate(entry.File.Stored()); deflated != nil {
+			raw, _ := f.OpenRaw()
+			passed, _ := ioutil.ReadAll(raw)
+			if !bytes.Equal(passed, deflated) {
+				t.Errorf("%s: compressed again", f.Name)
+			}
+		}
+	}
+}
+
+func TestWriteTarGz(t *testing.T) {
+	tree := codecTree(t)
+	var buff bytes.Buffer
+	if err := WriteTarGz(&buff, mountTree(t, tree), "/", nil); err != nil {
+		t.Fatal(err)
+	}
+	gz, err := gzip.NewReader(&buff)
+	if err != nil {
+		t.Fatal(err)
+	}
+	archive := tar.NewReader(gz)
+	names := []string{}
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, header.Name)
+		if !header.ModTime.Equal(buildTime) {
+			t.Errorf("%s has time %s", header.Name, header.ModTime)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		got, _ := ioutil.ReadAll(archive)
+		want, err := tree.Bytes(header.Name)
+		if err != nil || !bytes.Equal(got, want) {
+			t.Errorf("%s: contents differ", header.Name)
+		}
+	}
+	if got := strings.Join(names, ","); !strings.HasPrefix(got, "codec/,codec/deflate.txt,") || !strings.HasSuffix(got, ",empty/") {
+		t.Errorf("entries %s", got)
+	}
+}
+
+func TestArchiveServer(t *testing.T) {
+	filter := func(name string) bool { return strings.HasSuffix(name, ".txt") }
+	server := ArchiveServer(mountTree(t, codecTree(t)), filter)
+
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequest("GET", "/codec.zip", nil))
+	if w.Code != 200 || w.Header().Get("Content-Type") != "application/zip" ||
+		w.Header().Get("Content-Disposition") != "attachment; filename=codec.zip" {
+		t.Fatalf("%d %v", w.Code, w.Header())
+	}
+	archive, err := zip.NewReader(bytes.NewReader(w.Body.Bytes()), int64(w.Body.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range archive.File {
+		if !filter(f.Name) {
+			t.Errorf("filtered %s included", f.Name)
+		}
+	}
+	if len(archive.File) != 5 {
+		t.Errorf("%d entries", len(archive.File))
+	}
+
+	w = httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequest("GET", "/.tar.gz", nil))
+	if w.Code != 200 || w.Header().Get("Content-Disposition") != "attachment; filename=archive.tar.gz" {
+		t.Errorf("%d %v", w.Code, w.Header())
+	}
+
+	for _, p := range []string{"/nosuchdir.zip", "/codec/index.html.zip", "/codec", "/../codec.zip"} {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.URL.Path = p
+		w = httptest.NewRecorder()
+		server.ServeHTTP(w, r)
+		if w.Code != 404 {
+			t.Errorf("%s: %d", p, w.Code)
+		}
+	}
+}
+
+func TestArchiveServerNames(t *testing.T) {
+	dir, err := FromMap(map[string][]byte{`we"ird;x/a.txt`: []byte("a"), "café/a.txt": []byte("a")}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := ArchiveServer(mountTree(t, dir), nil)
+	for p, want := range map[string]string{
+		`/we"ird;x.zip`: `attachment; filename="we\"ird;x.zip"`,
+		"/café.tar.gz":  "attachment; filename*=utf-8''caf%C3%A9.tar.gz",
+	} {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.URL.Path = p
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, r)
+		disposition := w.Header().Get("Content-Disposition")
+		_, params, err := mime.ParseMediaType(disposition)
+		if w.Code != 200 || disposition != want || err != nil || "/"+params["filename"] != p {
+			t.Errorf("%s: %d %q, %v %v", p, w.Code, disposition, params, err)
+		}
+	}
+}
+
+// A file system whose files can't be opened.
+type unreadableFS struct {
+	http.FileSystem
+}
+
+func (fsys unreadableFS) Open(name string) (http.File, error) {
+	f, err := fsys.FileSystem.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	if stat, err := f.Stat(); err == nil && !stat.IsDir() {
+		f.Close()
+		return nil, errors.New("unreadable")
+	}
+	return f, nil
+}
+
+func TestArchiveServerFailsBeforeStarting(t *testing.T) {
+	server := ArchiveServer(unreadableFS{mountTree(t, codecTree(t))}, nil)
+	for _, p := range []string{"/codec.zip", "/codec.tar.gz"} {
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, httptest.NewRequest("GET", p, nil))
+		if w.Code != http.StatusInternalServerError || w.Header().Get("Content-Disposition") != "" ||
+			!strings.Contains(w.Body.String(), "unreadable") {
+			t.Errorf("%s: %d %v %q", p, w.Code, w.Header(), w.Body.String())
+		}
+	}
+}
@@ -0,0 +1,371 @@
+package embedfs
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// How trees are built at run time by FromMap, FromDir, FromZip and FromTar.
+// A nil *BuildOptions uses the zero value.
+type BuildOptions struct {
+	Name        string             // DirName of the root; FromDir defaults to the directory's base name, the others to "."
+	Compression *CompressionPolicy // how files are stored; nil stores them as they are
+	ModTime     time.Time          // for files and directories that come without a time of their own
+
+	// Leading path components dropped from every name, as by tar
+	// --strip-components; entries with no more components are left out.
+	StripComponents int
+}
+
+// Builds a tree from file contents keyed by slash-separated path.  Keys
+// ending in a slash stand for directories, so that empty ones can be given.
+// The contents are copied.
+func FromMap(files map[string][]byte, opts *BuildOptions) (*EmbedDir, error) {
+	b := newTreeBuilder(opts)
+	for name, data := range files {
+		var err error
+		if strings.HasSuffix(name, "/") {
+			err = b.addDir(name, time.Time{})
+		} else {
+			err = b.addFile(name, append([]byte(nil), data...), time.Time{}, name)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return b.build(), nil
+}
+
+// Builds a tree from the regular files below a directory on disk, keeping
+// their modification times.  Symbolic links and other special files are
+// left out.
+func FromDir(root string, opts *BuildOptions) (*EmbedDir, error) {
+	b := newTreeBuilder(opts)
+	if b.name == "" {
+		abs, err := filepath.Abs(root)
+		if err != nil {
+			return nil, err
+		}
+		b.name = filepath.Base(abs)
+	}
+	err := filepath.Walk(root, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		switch {
+		case info.IsDir():
+			return b.addDir(filepath.ToSlash(rel), info.ModTime())
+		case info.Mode().IsRegular():
+			data, err := ioutil.ReadFile(p)
+			if err != nil {
+				return err
+			}
+			return b.addFile(filepath.ToSlash(rel), data, info.ModTime(), p)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return b.build(), nil
+}
+
+// Builds a tree from a directory, or a zip, tar or gzipped tar archive
+// according to its extension: .zip, .tar, .tar.gz or .tgz.
+func ReadSource(name string) (*EmbedDir, error) {
+	if !IsArchive(name) {
+		return FromDir(name, nil)
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	switch {
+	case strings.HasSuffix(name, ".zip"):
+		stat, err := f.Stat()
+		if err != nil {
+			return nil, err
+		}
+		return FromZip(f, stat.Size(), nil)
+	case strings.HasSuffix(name, ".tar"):
+		return FromTar(f, nil)
+	}
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+	return FromTar(gz, nil)
+}
+
+// Reports whether ReadSource reads the name as an archive.
+func IsArchive(name string) bool {
+	for _, ext := range []string{".zip", ".tar", ".tar.gz", ".tgz"} {
+		if strings.HasSuffix(name, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// Cleans a name the way Open, Dir and the other entry points of a tree do:
+// returns the slash-separated path relative to the root, "." for the root,
+// or an error wrapping fs.ErrInvalid for names they reject.
+func CleanPath(name string) (string, error) {
+	return cleanPath("open", name)
+}
+
+// Builds a tree from a zip archive, keeping the modification times it
+// records.  Entries that would land outside the root are an error.
+func FromZip(r io.ReaderAt, size int64, opts *BuildOptions) (*EmbedDir, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+	b := newTreeBuilder(opts)
+	for _, f := range archive.File {
+		if strings.HasSuffix(f.Name, "/") {
+			if err := b.addDir(f.Name, f.Modified); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if !f.Mode().IsRegular() {
+			continue
+		}
+		data, err := readZipEntry(f)
+		if err != nil {
+			return nil, err
+		}
+		if err := b.addFile(f.Name, data, f.Modified, f.Name); err != nil {
+			return nil, err
+		}
+	}
+	return b.build(), nil
+}
+
+func readZipEntry(f *zip.File) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return ioutil.ReadAll(rc)
+}
+
+// Builds a tree from a tar stream, keeping the modification times it
+// records.  Only regular files and directories are taken; a name that
+// appears twice takes the later contents, as when extracting.  Compressed
+// archives are read through the matching decompressor, e.g. gzip.NewReader.
+// Entries that would land outside the root are an error.
+func FromTar(r io.Reader, opts *BuildOptions) (*EmbedDir, error) {
+	archive := tar.NewReader(r)
+	b := newTreeBuilder(opts)
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			err = b.addDir(header.Name, header.ModTime)
+		case tar.TypeReg:
+			var data []byte
+			if data, err = ioutil.ReadAll(archive); err == nil {
+				err = b.addFile(header.Name, data, header.ModTime, header.Name)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return b.build(), nil
+}
+
+// Collects files and directories, then lays them out the way the generator
+// does: every directory indexes every path below it.
+type treeBuilder struct {
+	name    string
+	policy  *CompressionPolicy
+	modTime time.Time
+	strip   int
+	root    *buildDir
+}
+
+type buildDir struct {
+	modTime time.Time // zero unless given
+	dirs    map[string]*buildDir
+	files   map[string]*EmbedFile
+}
+
+func newBuildDir() *buildDir {
+	return &buildDir{dirs: make(map[string]*buildDir), files: make(map[string]*EmbedFile)}
+}
+
+func newTreeBuilder(opts *BuildOptions) *treeBuilder {
+	if opts == nil {
+		opts = &BuildOptions{}
+	}
+	return &treeBuilder{
+		name:    opts.Name,
+		policy:  opts.Compression,
+		modTime: opts.ModTime,
+		strip:   opts.StripComponents,
+		root:    newBuildDir(),
+	}
+}
+
+// Returns the directory, creating it and its parents if need be.
+func (b *treeBuilder) dir(name, clean string) (*buildDir, error) {
+	d := b.root
+	if clean == "." {
+		return d, nil
+	}
+	for _, segment := range strings.Split(clean, "/") {
+		if _, isFile := d.files[segment]; isFile {
+			return nil, &os.PathError{Op: "build", Path: name, Err: fs.ErrExist}
+		}
+		child := d.dirs[segment]
+		if child == nil {
+			child = newBuildDir()
+			d.dirs[segment] = child
+		}
+		d = child
+	}
+	return d, nil
+}
+
+// Cleans a name and drops the components to strip.  Returns false if
+// nothing is left.
+func (b *treeBuilder) clean(name string) (string, bool, error) {
+	clean, err := cleanPath("build", name)
+	if err != nil || b.strip == 0 {
+		return clean, true, err
+	}
+	segments := strings.SplitN(clean, "/", b.strip+1)
+	if clean == "." || len(segments) <= b.strip {
+		return "", false, nil
+	}
+	return segments[b.strip], true, nil
+}
+
+func (b *treeBuilder) addDir(name string, modTime time.Time) error {
+	clean, ok, err := b.clean(name)
+	if !ok || err != nil {
+		return err
+	}
+	d, err := b.dir(name, clean)
+	if err != nil {
+		return err
+	}
+	d.modTime = modTime
+	return nil
+}
+
+// Adds a file, taking ownership of data.
+func (b *treeBuilder) addFile(name string, data []byte, modTime time.Time, original string) error {
+	clean, ok, err := b.clean(name)
+	if !ok || err != nil {
+		return err
+	}
+	if clean == "." {
+		return &os.PathError{Op: "build", Path: name, Err: fs.ErrInvalid}
+	}
+	d, err := b.dir(name, path.Dir(clean))
+	if err != nil {
+		return err
+	}
+	base := path.Base(clean)
+	if _, isDir := d.dirs[base]; isDir {
+		return &os.PathError{Op: "build", Path: name, Err: fs.ErrExist}
+	}
+
+	codec, stored := CodecNone, data
+	if b.policy != nil {
+		if codec, stored, err = b.policy.Compress(clean, data); err != nil {
+			return err
+		}
+	}
+	if modTime.IsZero() {
+		modTime = b.modTime
+	}
+	d.files[base] = &EmbedFile{
+		FileName:        base,
+		Original:        original,
+		Compressed:      codec != CodecNone,
+		Codec:           codec,
+		ModTimeUnixNano: unixNano(modTime),
+		OriginalSize:    int64(len(data)),
+		Data:            unsafeString(stored),
+	}
+	return nil
+}
+
+func (b *treeBuilder) build() *EmbedDir {
+	name := b.name
+	if name == "" {
+		name = "."
+	}
+	return b.root.embed(name, b.modTime)
+}
+
+// Directories without a time of their own take that of the newest file
+// below them, as generated ones do, or else the default.
+func (d *buildDir) embed(name string, defaultTime time.Time) *EmbedDir {
+	dir := &EmbedDir{DirName: name}
+	var newest int64
+	for base, f := range d.files {
+		dir.Entries = append(dir.Entries, Entry{Path: base, File: f})
+		if f.ModTimeUnixNano > newest {
+			newest = f.ModTimeUnixNano
+		}
+	}
+	for base, child := range d.dirs {
+		sub := child.embed(base, defaultTime)
+		dir.Entries = append(dir.Entries, Entry{Path: base, Dir: sub})
+		for _, e := range sub.Entries {
+			e.Path = base + "/" + e.Path
+			dir.Entries = append(dir.Entries, e)
+		}
+		if sub.ModTimeUnixNano > newest {
+			newest = sub.ModTimeUnixNano
+		}
+	}
+	sort.Slice(dir.Entries, func(i, j int) bool { return dir.Entries[i].Path < dir.Entries[j].Path })
+
+	switch {
+	case !d.modTime.IsZero():
+		dir.ModTimeUnixNano = unixNano(d.modTime)
+	case newest != 0:
+		dir.ModTimeUnixNano = newest
+	default:
+		dir.ModTimeUnixNano = unixNano(defaultTime)
+	}
+	return dir
+}
+
+// The zero time has no Unix time; it's stored as the epoch.
+func unixNano(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
@@ -0,0 +1,183 @@
+package embedfs
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+var buildFiles = map[string]string{
+	"index.html":        "<html></html>",
+	"css/style.css":     strings.Repeat("body { margin: 0; }\n", 1000),
+	"js/lib/jquery.js":  "jQuery",
+	"js/lib/empty.js":   "",
+	"img/icons/a/b.png": "\x89PNG",
+}
+
+var buildTime = time.Date(2020, 5, 17, 12, 0, 0, 0, time.UTC)
+
+// Checks the built tree holds exactly buildFiles, consistently enough for
+// fstest.TestFS.
+func checkBuilt(t *testing.T, dir *EmbedDir) {
+	t.Helper()
+	fsys := &ioFS{mountTree(t, dir)}
+	expected := []string{}
+	for name, want := range buildFiles {
+		expected = append(expected, name)
+		got, err := fs.ReadFile(fsys, name)
+		if err != nil || string(got) != want {
+			t.Errorf("%s: got %d bytes, %v; want %d bytes", name, len(got), err, len(want))
+		}
+	}
+	if err := fstest.TestFS(fsys, expected...); err != nil {
+		t.Error(err)
+	}
+	if got := listing(t, mountTree(t, dir), "js"); got != "lib" {
+		t.Errorf("js lists %s", got)
+	}
+}
+
+func TestFromMap(t *testing.T) {
+	files := map[string][]byte{"empty/": nil}
+	for name, content := range buildFiles {
+		files[name] = []byte(content)
+	}
+	policy := DefaultCompressionPolicy()
+	policy.Rules = []CompressionRule{{Pattern: "*.css", Mode: CompressAlways, Codec: CodecGzip, Level: 9}}
+	dir, err := FromMap(files, &BuildOptions{Compression: policy, ModTime: buildTime})
+	if err != nil {
+		t.Fatal(err)
+	}
+	delete(files, "empty/")
+	checkBuilt(t, dir)
+
+	files["index.html"][0] = 'X'
+	if data, _ := dir.Bytes("index.html"); data[0] != '<' {
+		t.Error("contents are shared with the map")
+	}
+	css, _ := dir.lookup("css/style.css")
+	if css.File.Codec != CodecGzip || css.File.Size() != int64(len(buildFiles["css/style.css"])) {
+		t.Errorf("style.css stored as %q, size %d", css.File.Codec, css.File.Size())
+	}
+	if !css.File.ModTime().Equal(buildTime) || !dir.ModTime().Equal(buildTime) {
+		t.Errorf("times: file %s, dir %s", css.File.ModTime(), dir.ModTime())
+	}
+	if empty, _ := dir.lookup("empty"); empty == nil || empty.Dir == nil {
+		t.Error("empty directory missing")
+	}
+}
+
+func TestFromDir(t *testing.T) {
+	root, err := ioutil.TempDir("", "embedfs-build")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	writeFiles(t, root, buildFiles)
+	if err := os.Chtimes(filepath.Join(root, "index.html"), buildTime, buildTime); err != nil {
+		t.Fatal(err)
+	}
+
+	dir, err := FromDir(root, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkBuilt(t, dir)
+	if dir.Name() != filepath.Base(root) {
+		t.Errorf("root is named %s", dir.Name())
+	}
+	index, _ := dir.lookup("index.html")
+	if !index.File.ModTime().Equal(buildTime) {
+		t.Errorf("index.html has time %s", index.File.ModTime())
+	}
+}
+
+func TestFromZip(t *testing.T) {
+	var buff bytes.Buffer
+	w := zip.NewWriter(&buff)
+	w.CreateHeader(&zip.FileHeader{Name: "js/", Modified: buildTime})
+	for name, content := range buildFiles {
+		f, err := w.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: buildTime})
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Write([]byte(content))
+	}
+	w.Close()
+
+	dir, err := FromZip(bytes.NewReader(buff.Bytes()), int64(buff.Len()), &BuildOptions{Name: "site"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkBuilt(t, dir)
+	js, _ := dir.lookup("js")
+	if dir.Name() != "site" || !js.Dir.ModTime().Equal(buildTime) {
+		t.Errorf("root %s, js has time %s", dir.Name(), js.Dir.ModTime())
+	}
+}
+
+func TestFromTar(t *testing.T) {
+	var buff bytes.Buffer
+	w := tar.NewWriter(&buff)
+	w.WriteHeader(&tar.Header{Name: "js/", Typeflag: tar.TypeDir, Mode: 0755, ModTime: buildTime})
+	w.WriteHeader(&tar.Header{Name: "link", Typeflag: tar.TypeSymlink, Linkname: "index.html"})
+	// Replaced by the later entry of the same name.
+	w.WriteHeader(&tar.Header{Name: "index.html", Typeflag: tar.TypeReg, Mode: 0644, Size: 3})
+	w.Write([]byte("old"))
+	for name, content := range buildFiles {
+		w.WriteHeader(&tar.Header{Name: name, Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(content)), ModTime: buildTime})
+		w.Write([]byte(content))
+	}
+	w.Close()
+
+	dir, err := FromTar(&buff, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkBuilt(t, dir)
+	if _, exists := dir.lookup("link"); exists {
+		t.Error("symbolic link was taken")
+	}
+}
+
+func TestStripComponents(t *testing.T) {
+	files := map[string][]byte{"README": nil, "top/": nil}
+	for name, content := range buildFiles {
+		files["top/"+name] = []byte(content)
+	}
+	dir, err := FromMap(files, &BuildOptions{StripComponents: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkBuilt(t, dir)
+}
+
+func TestBuildRejectsBadNames(t *testing.T) {
+	for _, name := range []string{"../evil", "a/../../evil", "a\\b"} {
+		if _, err := FromMap(map[string][]byte{name: nil}, nil); !errors.Is(err, fs.ErrInvalid) {
+			t.Errorf("%q: got %v, want fs.ErrInvalid", name, err)
+		}
+	}
+
+	var buff bytes.Buffer
+	w := zip.NewWriter(&buff)
+	w.Create("../../etc/passwd")
+	w.Close()
+	if _, err := FromZip(bytes.NewReader(buff.Bytes()), int64(buff.Len()), nil); !errors.Is(err, fs.ErrInvalid) {
+		t.Errorf("zip slip: got %v, want fs.ErrInvalid", err)
+	}
+
+	_, err := FromMap(map[string][]byte{"a": nil, "a/b": nil}, nil)
+	if !errors.Is(err, fs.ErrExist) {
+		t.Errorf("file and directory of the same name: got %v, want fs.ErrExist", err)
+	}
+}
@@ -0,0 +1,257 @@
+package embedfs
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/lzw"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// When to compress a file.
+type CompressionMode int
+
+const (
+	CompressAuto   CompressionMode = iota // only when large enough and the ratio is good enough
+	CompressNever                         // always store as is
+	CompressAlways                        // always store compressed
+)
+
+// Special codec name that tries every codec and keeps the smallest output.
+const CodecAuto = "auto"
+
+// Codecs tried when a rule asks for CodecAuto.
+var autoCodecs = []string{CodecZlib, CodecDeflate, CodecGzip, CodecLZW}
+
+func (m CompressionMode) String() string {
+	switch m {
+	case CompressNever:
+		return "never"
+	case CompressAlways:
+		return "always"
+	}
+	return "auto"
+}
+
+func parseCompressionMode(s string) (CompressionMode, error) {
+	switch s {
+	case "auto":
+		return CompressAuto, nil
+	case "never":
+		return CompressNever, nil
+	case "always":
+		return CompressAlways, nil
+	}
+	return CompressAuto, errors.New("unknown compression mode: " + s)
+}
+
+// A compression rule applied to files matching Pattern.  Patterns without a
+// slash are matched against the base name of the file, otherwise against the
+// whole source path, using path.Match syntax.  Paths are matched
+// slash-separated, as names from archives always are.
+type CompressionRule struct {
+	Pattern string
+	Mode    CompressionMode
+	Codec   string // one of the Codec constants, or CodecAuto
+	Level   int    // flate level; ignored by lzw
+}
+
+func (r CompressionRule) String() string {
+	return fmt.Sprintf("%s=%s:%s:%d", r.Pattern, r.Mode, r.Codec, r.Level)
+}
+
+func (r CompressionRule) matches(name string) bool {
+	name = filepath.ToSlash(name)
+	if !strings.ContainsRune(r.Pattern, '/') {
+		name = path.Base(name)
+	}
+	matched, _ := path.Match(r.Pattern, name)
+	return matched
+}
+
+// Parses a rule of the form glob=mode[:codec[:level]], e.g. "*.png=never" or
+// "*.js=always:gzip:9".  Missing codec and level default to zlib and the
+// default compression level.
+func ParseCompressionRule(s string) (rule CompressionRule, err error) {
+	eq := strings.LastIndex(s, "=")
+	if eq <= 0 {
+		return rule, errors.New("expecting glob=mode[:codec[:level]]: " + s)
+	}
+	rule.Pattern = s[:eq]
+	if _, err = path.Match(rule.Pattern, ""); err != nil {
+		return
+	}
+	rule.Codec = CodecZlib
+	rule.Level = flate.DefaultCompression
+
+	parts := strings.Split(s[eq+1:], ":")
+	if len(parts) > 3 {
+		return rule, errors.New("expecting glob=mode[:codec[:level]]: " + s)
+	}
+	if rule.Mode, err = parseCompressionMode(parts[0]); err != nil {
+		return
+	}
+	if len(parts) > 1 && parts[1] != "" {
+		if rule.Codec, err = checkCodec(parts[1]); err != nil {
+			return
+		}
+	}
+	if len(parts) > 2 && parts[2] != "" {
+		if rule.Level, err = strconv.Atoi(parts[2]); err != nil {
+			return
+		}
+	}
+	err = checkLevel(rule.Level)
+	return
+}
+
+func checkCodec(codec string) (string, error) {
+	switch codec {
+	case CodecAuto, CodecZlib, CodecDeflate, CodecGzip, CodecLZW:
+		return codec, nil
+	}
+	return codec, errors.New("unknown codec: " + codec)
+}
+
+func checkLevel(level int) error {
+	if level < flate.HuffmanOnly || level > flate.BestCompression {
+		return fmt.Errorf("compression level %d out of range [%d, %d]",
+			level, flate.HuffmanOnly, flate.BestCompression)
+	}
+	return nil
+}
+
+// Decides how each file is stored.  The first rule matching a file wins;
+// files matching no rule use Default.  In CompressAuto mode, files smaller
+// than MaxUncompressed bytes, or that don't shrink to MinRatio of their size,
+// are stored uncompressed.
+type CompressionPolicy struct {
+	Rules           []CompressionRule
+	Default         CompressionRule
+	MaxUncompressed int64
+	MinRatio        float64
+}
+
+// The policy matching the generator's historical behaviour: zlib in auto
+// mode for files of 5K or more that compress to half their size.
+func DefaultCompressionPolicy() *CompressionPolicy {
+	return &CompressionPolicy{
+		Default: CompressionRule{
+			Pattern: "*",
+			Mode:    CompressAuto,
+			Codec:   CodecZlib,
+			Level:   flate.DefaultCompression,
+		},
+		MaxUncompressed: 5 << 10,
+		MinRatio:        0.5,
+	}
+}
+
+// Implements flag.Value so that rules can be given repeatedly on the
+// command line.
+func (p *CompressionPolicy) String() string {
+	if p == nil {
+		return ""
+	}
+	rules := make([]string, len(p.Rules))
+	for i, r := range p.Rules {
+		rules[i] = r.String()
+	}
+	return strings.Join(rules, ",")
+}
+
+func (p *CompressionPolicy) Set(s string) error {
+	rule, err := ParseCompressionRule(s)
+	if err != nil {
+		return err
+	}
+	p.Rules = append(p.Rules, rule)
+	return nil
+}
+
+// Returns the rule that applies to the given source path.
+func (p *CompressionPolicy) Rule(path string) CompressionRule {
+	for _, r := range p.Rules {
+		if r.matches(path) {
+			return r
+		}
+	}
+	return p.Default
+}
+
+// Compresses data with the given codec.
+func compress(data io.Reader, codec string, level int) ([]byte, int64, error) {
+	var compressed bytes.Buffer
+	var out io.WriteCloser
+	var err error
+	switch codec {
+	case CodecZlib:
+		out, err = zlib.NewWriterLevel(&compressed, level)
+	case CodecDeflate:
+		out, err = flate.NewWriter(&compressed, level)
+	case CodecGzip:
+		out, err = gzip.NewWriterLevel(&compressed, level)
+	case CodecLZW:
+		out = lzw.NewWriter(&compressed, lzw.LSB, 8)
+	default:
+		err = errors.New("unknown codec: " + codec)
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	n, err := io.Copy(out, data)
+	if err != nil {
+		return nil, n, err
+	}
+	if err = out.Close(); err != nil {
+		return nil, n, err
+	}
+	return compressed.Bytes(), n, nil
+}
+
+// Decides how to store data read from the given source path.  Returns the
+// codec, or CodecNone to store the data as it is, and the data to store.
+func (p *CompressionPolicy) Compress(path string, original []byte) (codec string, data []byte, err error) {
+	rule := p.Rule(path)
+	size := int64(len(original))
+	if rule.Mode == CompressNever || rule.Mode == CompressAuto && size < p.MaxUncompressed {
+		return CodecNone, original, nil
+	}
+	if codec, data, err = compressData(original, rule); err != nil {
+		return
+	}
+	if rule.Mode == CompressAuto && float64(len(data))/float64(size) > p.MinRatio {
+		return CodecNone, original, nil
+	}
+	return
+}
+
+// Compress the data as the rule says.  For CodecAuto, every codec is tried
+// and the smallest output is kept.
+func compressData(original []byte, rule CompressionRule) (codec string, data []byte, err error) {
+	if rule.Mode == CompressNever {
+		return CodecNone, nil, nil
+	}
+
+	codecs := []string{rule.Codec}
+	if rule.Codec == CodecAuto {
+		codecs = autoCodecs
+	}
+	for _, c := range codecs {
+		zb, _, err := compress(bytes.NewReader(original), c, rule.Level)
+		if err != nil {
+			return CodecNone, nil, err
+		}
+		if data == nil || len(zb) < len(data) {
+			codec, data = c, zb
+		}
+	}
+	return
+}
@@ -0,0 +1,107 @@
+package embedfs
+
+import (
+	"bytes"
+	"compress/flate"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestParseCompressionRule(t *testing.T) {
+	for s, want := range map[string]CompressionRule{
+		"*.png=never":             {Pattern: "*.png", Mode: CompressNever, Codec: CodecZlib, Level: flate.DefaultCompression},
+		"*.js=always:gzip:9":      {Pattern: "*.js", Mode: CompressAlways, Codec: CodecGzip, Level: 9},
+		"css/*.css=auto:auto":     {Pattern: "css/*.css", Mode: CompressAuto, Codec: CodecAuto, Level: flate.DefaultCompression},
+		"a=b.txt=always::1":       {Pattern: "a=b.txt", Mode: CompressAlways, Codec: CodecZlib, Level: 1},
+		"*=auto:lzw":              {Pattern: "*", Mode: CompressAuto, Codec: CodecLZW, Level: flate.DefaultCompression},
+		"*.svg=always:deflate:-2": {Pattern: "*.svg", Mode: CompressAlways, Codec: CodecDeflate, Level: flate.HuffmanOnly},
+	} {
+		rule, err := ParseCompressionRule(s)
+		if err != nil || rule != want {
+			t.Errorf("%s: got %+v, %v, want %+v", s, rule, err, want)
+		}
+		if again, _ := ParseCompressionRule(rule.String()); again != rule {
+			t.Errorf("%s: %s does not parse back", s, rule)
+		}
+	}
+	for _, s := range []string{
+		"*.png", "=never", "*.png=sometimes", "[=never", "*.js=always:brotli",
+		"*.js=always:gzip:high", "*.js=always:gzip:10", "*.js=always:gzip:-3", "*.js=always:gzip:9:x",
+	} {
+		if _, err := ParseCompressionRule(s); err == nil {
+			t.Errorf("%s: parsed", s)
+		}
+	}
+}
+
+func TestCompressionPolicyRule(t *testing.T) {
+	policy := DefaultCompressionPolicy()
+	for _, s := range []string{"*.min.js=never", "*.js=always:gzip", "css/*.css=always:lzw", "/abs/*.html=never", `\*.css=never`} {
+		if err := policy.Set(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The first rule matching wins; patterns with a slash match whole paths,
+	// others base names, the same on every platform.
+	for p, want := range map[string]string{
+		"js/jquery.min.js": "*.min.js",
+		"js/app.js":        "*.js",
+		"css/style.css":    "css/*.css",
+		"vendor/css/a.css": "*",
+		"/abs/index.html":  "/abs/*.html",
+		"index.html":       "*",
+		"img/*.css":        `\*.css`,
+	} {
+		if got := policy.Rule(p).Pattern; got != want {
+			t.Errorf("%s: rule %s, want %s", p, got, want)
+		}
+	}
+}
+
+func TestCompressionPolicyCompress(t *testing.T) {
+	text := []byte(strings.Repeat("body { margin: 0; }\n", 1000))
+	random := make([]byte, 8<<10)
+	rand.New(rand.NewSource(1)).Read(random)
+	policy := DefaultCompressionPolicy()
+	for _, s := range []string{"*.png=never", "*.bin=always:deflate", "*.any=always:auto"} {
+		if err := policy.Set(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, c := range []struct {
+		path  string
+		data  []byte
+		codec string
+	}{
+		{"style.css", text, CodecZlib},
+		{"small.css", text[:5<<10-1], CodecNone}, // under MaxUncompressed
+		{"random.css", random, CodecNone},        // not shrinking to MinRatio
+		{"big.png", text, CodecNone},             // never
+		{"random.bin", random, CodecDeflate},     // always, however poor the ratio
+		{"tiny.bin", []byte("x"), CodecDeflate},  // always, however small
+	} {
+		codec, data, err := policy.Compress(c.path, c.data)
+		if err != nil || codec != c.codec {
+			t.Errorf("%s: got %q, %v, want %q", c.path, codec, err, c.codec)
+		}
+		if codec == CodecNone && !bytes.Equal(data, c.data) {
+			t.Errorf("%s: stored data not the original", c.path)
+		}
+	}
+
+	// CodecAuto keeps the smallest output of all codecs.
+	codec, data, err := policy.Compress("style.any", text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range autoCodecs {
+		other, _, err := compress(bytes.NewReader(text), c, flate.DefaultCompression)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(other) < len(data) {
+			t.Errorf("auto kept %s of %d bytes, %s makes %d", codec, len(data), c, len(other))
+		}
+	}
+}
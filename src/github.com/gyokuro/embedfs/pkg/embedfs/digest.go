@@ -0,0 +1,8 @@
+// AUTO-GENERATED BY embedfs bootstrap
+// DO NOT EDIT!!!
+package embedfs
+
+// SHA-256 of fs.go, the runtime copied into generated code, as of the last
+// `embedfs bootstrap`.  The embedfs command carries a copy of fs.go and warns
+// when that copy has another digest.
+const TemplateDigest = "9c45c36eaa780e7149a6c28036f0e257f5e58d13580e6befbd48ecc72f42ef65"
@@ -0,0 +1,304 @@
+package embedfs
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+)
+
+// What Extract does about files already at the destination.
+type OverwritePolicy int
+
+const (
+	OverwriteNever   OverwritePolicy = iota // fail, before writing anything
+	OverwriteSkip                           // leave them as they are
+	OverwriteIfNewer                        // replace them if the extracted file is newer
+	OverwriteAlways                         // replace them
+)
+
+var overwritePolicies = []string{"never", "skip", "newer", "always"}
+
+func (p OverwritePolicy) String() string {
+	if p < 0 || int(p) >= len(overwritePolicies) {
+		return fmt.Sprintf("OverwritePolicy(%d)", int(p))
+	}
+	return overwritePolicies[p]
+}
+
+// Implements flag.Value, taking never, skip, newer or always.
+func (p *OverwritePolicy) Set(s string) error {
+	for i, name := range overwritePolicies {
+		if s == name {
+			*p = OverwritePolicy(i)
+			return nil
+		}
+	}
+	return errors.New("unknown overwrite policy: " + s)
+}
+
+// Returned, wrapped, when an extracted file doesn't have the size recorded
+// when the tree was built.
+var ErrSizeMismatch = errors.New("size does not match")
+
+// How Extract writes files.  A nil *ExtractOptions uses the zero value.
+type ExtractOptions struct {
+	Overwrite OverwritePolicy
+	Filter    func(name string) bool // given the slash-separated path of each file; nil takes every file
+}
+
+type extractItem struct {
+	name   string
+	info   os.FileInfo
+	exists bool // already at the destination
+}
+
+// Writes the files of a tree, decompressed, below destDir, with their
+// modification times, and checks each has the size recorded when the tree
+// was built.  Directories are created as needed; those created take their
+// times from the tree too.
+//
+// Files are first written to a staging directory next to destDir and only
+// moved into place once all of them have been written and checked, so a
+// file that can't be read or has the wrong size leaves the destination as
+// it was.  If destDir doesn't exist, the staging directory becomes destDir
+// in a single rename.  Otherwise files are moved one by one, and if a move
+// fails those already moved are moved back.
+func Extract(fsys http.FileSystem, destDir string, opts *ExtractOptions) error {
+	if opts == nil {
+		opts = &ExtractOptions{}
+	}
+	var files, dirs []extractItem
+	err := walkTree(fsys, ".", func(name string, info os.FileInfo) error {
+		target := filepath.Join(destDir, filepath.FromSlash(name))
+		existing, err := os.Lstat(target)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		item := extractItem{name: name, info: info, exists: err == nil}
+		if info.IsDir() {
+			if item.exists && !existing.IsDir() {
+				return &os.PathError{Op: "extract", Path: target, Err: os.ErrExist}
+			}
+			dirs = append(dirs, item)
+			return nil
+		}
+		if opts.Filter != nil && !opts.Filter(name) {
+			return nil
+		}
+		if item.exists {
+			switch {
+			case existing.IsDir():
+				return &os.PathError{Op: "extract", Path: target, Err: errIsDir}
+			case opts.Overwrite == OverwriteNever:
+				return &os.PathError{Op: "extract", Path: target, Err: os.ErrExist}
+			case opts.Overwrite == OverwriteSkip,
+				opts.Overwrite == OverwriteIfNewer && !info.ModTime().After(existing.ModTime()):
+				return nil
+			}
+		}
+		files = append(files, item)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	// Empty directories are only taken when every file is.
+	allDirs := opts.Filter == nil
+	if len(files) == 0 && !allDirs {
+		return nil
+	}
+
+	destDir = filepath.Clean(destDir)
+	if err := os.MkdirAll(filepath.Dir(destDir), 0755); err != nil {
+		return err
+	}
+	staging, err := ioutil.TempDir(filepath.Dir(destDir), "."+filepath.Base(destDir)+"-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(staging)
+	if err := os.Chmod(staging, 0755); err != nil {
+		return err
+	}
+
+	for _, f := range files {
+		if err := stageFile(fsys, f, filepath.Join(staging, filepath.FromSlash(f.name))); err != nil {
+			return err
+		}
+	}
+	if allDirs {
+		for _, d := range dirs {
+			if err := os.MkdirAll(filepath.Join(staging, filepath.FromSlash(d.name)), 0755); err != nil {
+				return err
+			}
+		}
+	}
+
+	if _, err := os.Lstat(destDir); os.IsNotExist(err) {
+		if err := rename(staging, destDir); err != nil {
+			return err
+		}
+	} else if err := moveInto(staging, destDir, files, dirs, allDirs); err != nil {
+		return err
+	}
+
+	// Last, since adding files changes the times of directories.  Deepest
+	// first, so that setting one isn't undone by its children.
+	sort.Slice(dirs, func(i, j int) bool { return dirs[i].name > dirs[j].name })
+	for _, d := range dirs {
+		target := filepath.Join(destDir, filepath.FromSlash(d.name))
+		if d.exists {
+			continue
+		}
+		if _, err := os.Stat(target); err != nil {
+			continue // left out by the filter
+		}
+		if err := os.Chtimes(target, d.info.ModTime(), d.info.ModTime()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Renames files; replaced in tests.
+var rename = os.Rename
+
+// Moves the staged files into the existing destDir, the files they replace
+// aside until all are in place, and creates the directories missing.  On
+// failure, puts back what was there: files moved in are removed, those they
+// replaced moved back, and the directories created removed.
+func moveInto(staging, destDir string, files, dirs []extractItem, allDirs bool) (err error) {
+	aside, err := ioutil.TempDir(filepath.Dir(destDir), "."+filepath.Base(destDir)+"-old-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(aside)
+	var moved []extractItem
+	defer func() {
+		if err == nil {
+			return
+		}
+		for i := len(moved) - 1; i >= 0; i-- {
+			target := filepath.Join(destDir, filepath.FromSlash(moved[i].name))
+			os.Remove(target)
+			if moved[i].exists {
+				rename(filepath.Join(aside, filepath.FromSlash(moved[i].name)), target)
+			}
+		}
+		// Deepest first; those still holding files weren't created.
+		created := []string{}
+		for _, d := range dirs {
+			if !d.exists {
+				created = append(created, d.name)
+			}
+		}
+		sort.Sort(sort.Reverse(sort.StringSlice(created)))
+		for _, name := range created {
+			os.Remove(filepath.Join(destDir, filepath.FromSlash(name)))
+		}
+	}()
+
+	for _, f := range files {
+		target := filepath.Join(destDir, filepath.FromSlash(f.name))
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		old := filepath.Join(aside, filepath.FromSlash(f.name))
+		if f.exists {
+			if err := os.MkdirAll(filepath.Dir(old), 0755); err != nil {
+				return err
+			}
+			if err := rename(target, old); err != nil {
+				return err
+			}
+		}
+		if err := rename(filepath.Join(staging, filepath.FromSlash(f.name)), target); err != nil {
+			if f.exists {
+				rename(old, target)
+			}
+			return err
+		}
+		moved = append(moved, f)
+	}
+	for _, d := range dirs {
+		if allDirs && !d.exists {
+			if err := os.MkdirAll(filepath.Join(destDir, filepath.FromSlash(d.name)), 0755); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Writes one file and checks its size.
+func stageFile(fsys http.FileSystem, item extractItem, target string) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	src, err := fsys.Open(item.name)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	out, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+	written, err := io.Copy(out, src)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if written != item.info.Size() {
+		return &os.PathError{Op: "extract", Path: item.name,
+			Err: fmt.Errorf("%w: wrote %d bytes, expected %d", ErrSizeMismatch, written, item.info.Size())}
+	}
+	return os.Chtimes(target, item.info.ModTime(), item.info.ModTime())
+}
+
+// Calls fn for every file and directory below name, parents first, with
+// slash-separated paths relative to the root.  The root itself is passed as
+// ".".
+func walkTree(fsys http.FileSystem, name string, fn func(name string, info os.FileInfo) error) error {
+	dir, err := fsys.Open(name)
+	if err != nil {
+		return err
+	}
+	stat, err := dir.Stat()
+	if err != nil {
+		dir.Close()
+		return err
+	}
+	if !stat.IsDir() {
+		dir.Close()
+		return fn(name, stat)
+	}
+	infos, err := dir.Readdir(-1)
+	dir.Close()
+	if err != nil {
+		return err
+	}
+	if err := fn(name, stat); err != nil {
+		return err
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name() < infos[j].Name() })
+	for _, info := range infos {
+		child := path.Join(name, info.Name())
+		if info.IsDir() {
+			if err := walkTree(fsys, child, fn); err != nil {
+				return err
+			}
+		} else if err := fn(child, info); err != nil {
+			return err
+		}
+	}
+	return nil
+}
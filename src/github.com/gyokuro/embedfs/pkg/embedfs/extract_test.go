@@ -0,0 +1,179 @@
+package embedfs
+
+import (
+	"errors"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func extractTree(t *testing.T) *EmbedDir {
+	files := map[string][]byte{"empty/": nil}
+	for name, content := range buildFiles {
+		files[name] = []byte(content)
+	}
+	policy := DefaultCompressionPolicy()
+	policy.Rules = []CompressionRule{{Pattern: "*", Mode: CompressAlways, Codec: CodecDeflate, Level: 9}}
+	dir, err := FromMap(files, &BuildOptions{Compression: policy, ModTime: buildTime})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "embedfs-extract")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func checkExtracted(t *testing.T, dest string, names ...string) {
+	t.Helper()
+	for _, name := range names {
+		p := filepath.Join(dest, filepath.FromSlash(name))
+		data, err := ioutil.ReadFile(p)
+		if err != nil || string(data) != buildFiles[name] {
+			t.Errorf("%s: got %d bytes, %v", name, len(data), err)
+			continue
+		}
+		if stat, _ := os.Stat(p); !stat.ModTime().Equal(buildTime) {
+			t.Errorf("%s has time %s", name, stat.ModTime())
+		}
+	}
+}
+
+func TestExtract(t *testing.T) {
+	tmp := tempDir(t)
+	defer os.RemoveAll(tmp)
+	dest := filepath.Join(tmp, "out", "site")
+
+	if err := Extract(mountTree(t, extractTree(t)), dest, nil); err != nil {
+		t.Fatal(err)
+	}
+	names := []string{}
+	for name := range buildFiles {
+		names = append(names, name)
+	}
+	checkExtracted(t, dest, names...)
+	for _, name := range []string{".", "js/lib", "empty"} {
+		stat, err := os.Stat(filepath.Join(dest, name))
+		if err != nil || !stat.IsDir() || !stat.ModTime().Equal(buildTime) {
+			t.Errorf("directory %s: %v, %v", name, stat, err)
+		}
+	}
+	if left, _ := filepath.Glob(filepath.Join(tmp, "out", ".site-*")); len(left) > 0 {
+		t.Errorf("staging left behind: %s", left)
+	}
+}
+
+func TestExtractOverwrite(t *testing.T) {
+	older, newer := buildTime.Add(-time.Hour), buildTime.Add(time.Hour)
+	for _, c := range []struct {
+		policy   OverwritePolicy
+		mtime    time.Time
+		replaced bool
+	}{
+		{OverwriteSkip, older, false},
+		{OverwriteIfNewer, older, true},
+		{OverwriteIfNewer, newer, false},
+		{OverwriteAlways, newer, true},
+	} {
+		dest := tempDir(t)
+		writeFiles(t, dest, map[string]string{"index.html": "local"})
+		os.Chtimes(filepath.Join(dest, "index.html"), c.mtime, c.mtime)
+
+		if err := Extract(mountTree(t, extractTree(t)), dest, &ExtractOptions{Overwrite: c.policy}); err != nil {
+			t.Fatal(err)
+		}
+		data, _ := ioutil.ReadFile(filepath.Join(dest, "index.html"))
+		if replaced := string(data) != "local"; replaced != c.replaced {
+			t.Errorf("%s over a file from %s: replaced %v", c.policy, c.mtime, replaced)
+		}
+		checkExtracted(t, dest, "css/style.css")
+		os.RemoveAll(dest)
+	}
+
+	dest := tempDir(t)
+	defer os.RemoveAll(dest)
+	writeFiles(t, dest, map[string]string{"index.html": "local"})
+	err := Extract(mountTree(t, extractTree(t)), dest, &ExtractOptions{Overwrite: OverwriteNever})
+	if !errors.Is(err, fs.ErrExist) {
+		t.Errorf("got %v, want fs.ErrExist", err)
+	}
+	if _, err := os.Stat(filepath.Join(dest, "css")); err == nil {
+		t.Error("files written despite the conflict")
+	}
+}
+
+func TestExtractFilter(t *testing.T) {
+	dest := filepath.Join(tempDir(t), "site")
+	defer os.RemoveAll(filepath.Dir(dest))
+	filter := func(name string) bool { return path.Ext(name) == ".js" }
+
+	if err := Extract(mountTree(t, extractTree(t)), dest, &ExtractOptions{Filter: filter}); err != nil {
+		t.Fatal(err)
+	}
+	checkExtracted(t, dest, "js/lib/jquery.js", "js/lib/empty.js")
+	for _, name := range []string{"index.html", "css", "empty"} {
+		if _, err := os.Stat(filepath.Join(dest, name)); err == nil {
+			t.Errorf("%s extracted", name)
+		}
+	}
+}
+
+func TestExtractVerifiesSizes(t *testing.T) {
+	tree := extractTree(t)
+	entry, _ := tree.lookup("index.html")
+	broken := entry.File
+	broken.Codec, broken.Compressed, broken.Data = CodecNone, false, buildFiles["index.html"]
+	broken.OriginalSize--
+
+	dest := filepath.Join(tempDir(t), "site")
+	defer os.RemoveAll(filepath.Dir(dest))
+	if err := Extract(mountTree(t, tree), dest, nil); !errors.Is(err, ErrSizeMismatch) {
+		t.Errorf("got %v, want ErrSizeMismatch", err)
+	}
+	if _, err := os.Stat(dest); err == nil {
+		t.Error("destination created despite the mismatch")
+	}
+}
+
+func TestExtractRollsBack(t *testing.T) {
+	dest := tempDir(t)
+	defer os.RemoveAll(dest)
+	writeFiles(t, dest, map[string]string{"index.html": "local", "js/app.js": "app"})
+	failing := errors.New("rename failed")
+	defer func() { rename = os.Rename }()
+	rename = func(from, to string) error {
+		if filepath.Base(to) == "jquery.js" {
+			return failing
+		}
+		return os.Rename(from, to)
+	}
+
+	err := Extract(mountTree(t, extractTree(t)), dest, &ExtractOptions{Overwrite: OverwriteAlways})
+	if !errors.Is(err, failing) {
+		t.Fatalf("got %v, want the rename error", err)
+	}
+	// Files moved in before the failure, index.html among them, are undone.
+	if data, _ := ioutil.ReadFile(filepath.Join(dest, "index.html")); string(data) != "local" {
+		t.Errorf("index.html holds %q", data)
+	}
+	for _, name := range []string{"css", "js/lib", "empty", "img"} {
+		if _, err := os.Stat(filepath.Join(dest, name)); err == nil {
+			t.Errorf("%s left behind", name)
+		}
+	}
+	if data, _ := ioutil.ReadFile(filepath.Join(dest, "js", "app.js")); string(data) != "app" {
+		t.Errorf("js/app.js holds %q", data)
+	}
+	if left, _ := filepath.Glob(filepath.Join(filepath.Dir(dest), "."+filepath.Base(dest)+"-*")); len(left) > 0 {
+		t.Errorf("staging left behind: %s", left)
+	}
+}
@@ -1,27 +1,24 @@
 package embedfs
 
 import (
-	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/lzw"
 	"compress/zlib"
+	"container/list"
 	"errors"
 	"io"
+	"io/fs"
 	"net/http"
 	"os"
-	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
+	"unicode/utf8"
+	"unsafe"
 )
 
-func DirAlloc(name string) *_dir {
-	return &_dir{
-		name:    name,
-		modTime: time.Now(),
-		dirs:    make(map[string]*_dir),
-		files:   make(map[string]*EmbedFile),
-	}
-}
-
 // http.FileSystem
 // type FileSystem interface {
 // 	Open(name string) (File, error)
@@ -49,139 +46,293 @@ func DirAlloc(name string) *_dir {
 // Ensures proper implementation of interfaces
 var _ http.FileSystem = (*_dirHandle)(nil)
 var _ http.File = (*fileHandle)(nil)
-var _ os.FileInfo = (*_dir)(nil)
+var _ fs.ReadDirFile = (*_dirHandle)(nil)
+var _ io.ReaderAt = (*fileHandle)(nil)
+var _ io.WriterTo = (*fileHandle)(nil)
+var _ os.FileInfo = (*EmbedDir)(nil)
 var _ os.FileInfo = (*EmbedFile)(nil)
 
+////////////////////////////////////////////////////////////////////////
+// FORMAT
+
+// Version of the generated code this runtime reads: the EmbedDir, Entry and
+// EmbedFile literals and the codecs of their data.  Changed only when they
+// change in a way older runtimes can't read.
+const FormatVersion = 1
+
+// Panics unless generated code of the given format version can be read by
+// this runtime.  Each generated TOC calls it at init, so that a package
+// generated for another runtime fails at start up rather than on first use.
+func RequireFormat(version int) bool {
+	if version != FormatVersion {
+		panic("embedfs: generated code has format version " + strconv.Itoa(version) +
+			" but the runtime reads version " + strconv.Itoa(FormatVersion) + "; regenerate it")
+	}
+	return true
+}
+
 ////////////////////////////////////////////////////////////////////////
 // DIRECTORY
 
-type _dir struct {
-	name    string
-	modTime time.Time
-	files   map[string]*EmbedFile
-	dirs    map[string]*_dir
-	sync    sync.Mutex
+// A file or directory in an EmbedDir index.  Exactly one of File and Dir is
+// set.
+type Entry struct {
+	Path string // slash-separated, relative to the directory holding the index
+	File *EmbedFile
+	Dir  *EmbedDir
+}
+
+// An embedded directory.  Entries lists every file and directory below it,
+// sorted by path.  The generator emits the whole tree as static data: nothing
+// is registered at init and nothing is mutated afterwards, so lookups need no
+// locking.  The hash index and the listing of the directory's own children
+// are built once, on first use.
+type EmbedDir struct {
+	DirName         string
+	ModTimeUnixNano int64
+	Entries         []Entry
+
+	once    sync.Once
+	paths   map[string]int // position of each path in Entries
+	listing []os.FileInfo  // children, sorted by name
 }
 
-func (d *_dir) Name() string {
-	return d.name
+func (d *EmbedDir) Name() string {
+	return d.DirName
 }
-func (d *_dir) Size() int64 {
+func (d *EmbedDir) Size() int64 {
 	return 0
 }
-func (d *_dir) Mode() os.FileMode {
+func (d *EmbedDir) Mode() os.FileMode {
 	return 0444 | os.ModeDir
 }
-func (d *_dir) ModTime() time.Time {
-	return d.modTime
+func (d *EmbedDir) ModTime() time.Time {
+	return time.Unix(0, d.ModTimeUnixNano)
 }
-func (d *_dir) IsDir() bool {
+func (d *EmbedDir) IsDir() bool {
 	return true
 }
-func (d *_dir) Sys() interface{} {
+func (d *EmbedDir) Sys() interface{} {
 	return nil
 }
-func (d *_dir) Open() (*_dirHandle, error) {
-	files := make([]os.FileInfo, 0)
-	for _, dir := range d.dirs {
-		if dir.name != d.name {
-			files = append(files, dir)
-		}
-	}
-	for _, file := range d.files {
-		files = append(files, file)
-	}
+func (d *EmbedDir) Open() (*_dirHandle, error) {
+	d.once.Do(d.buildIndex)
 	return &_dirHandle{
-		stat:  d,
-		files: files,
+		stat:      d,
+		dirReader: dirReader{files: d.listing},
 	}, nil
 }
 
-func (dir *_dir) AddFile(file *EmbedFile) {
-	dir.sync.Lock()
-	dir.files[file.FileName] = file
-	dir.sync.Unlock()
+func (d *EmbedDir) buildIndex() {
+	d.paths = make(map[string]int, len(d.Entries))
+	d.listing = make([]os.FileInfo, 0)
+	for i := range d.Entries {
+		e := &d.Entries[i]
+		d.paths[e.Path] = i
+		if strings.IndexByte(e.Path, '/') >= 0 {
+			continue
+		}
+		// Entries are sorted by path, so children come out sorted by name.
+		if e.Dir != nil {
+			d.listing = append(d.listing, e.Dir)
+		} else {
+			d.listing = append(d.listing, e.File)
+		}
+	}
 }
 
-func (dir *_dir) AddDir(subdir *_dir) {
-	dir.sync.Lock()
-	dir.dirs[subdir.name] = subdir
-	dir.sync.Unlock()
+// Finds the entry for a slash-separated path relative to this directory.
+func (d *EmbedDir) lookup(name string) (*Entry, bool) {
+	d.once.Do(d.buildIndex)
+	if i, exists := d.paths[name]; exists {
+		return &d.Entries[i], true
+	}
+	return nil, false
 }
 
-type _dirHandle struct {
-	stat   *_dir
-	offset int
-	files  []os.FileInfo // for implementing Readdir
+// Returns a copy of the contents of the named file.
+func (d *EmbedDir) ReadFile(name string) ([]byte, error) {
+	data, err := d.Bytes(name)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(nil), data...), nil
 }
 
-func (d *_dirHandle) Open(name string) (handle http.File, err error) {
-	if name == "." {
-		return d, nil
+// Returns the contents of the named file without copying; see
+// EmbedFile.Bytes.
+func (d *EmbedDir) Bytes(name string) ([]byte, error) {
+	clean, err := cleanPath("read", name)
+	if err != nil {
+		return nil, err
 	}
+	entry, exists := d.lookup(clean)
+	if !exists || entry.File == nil {
+		return nil, &os.PathError{Op: "read", Path: name, Err: os.ErrNotExist}
+	}
+	return entry.File.Bytes()
+}
 
-	name = filepath.Clean(name)
-	if filepath.IsAbs(name) {
-		name, err = filepath.Rel("/", name)
-		if err != nil {
-			return
-		}
+// Returns a file system rooted at the named directory below this one.
+func (d *EmbedDir) Sub(dir string) (http.FileSystem, error) {
+	clean, err := cleanPath("sub", dir)
+	if err != nil {
+		return nil, err
+	}
+	if clean == "." {
+		return d.Open()
+	}
+	entry, exists := d.lookup(clean)
+	if !exists || entry.Dir == nil {
+		return nil, &os.PathError{Op: "sub", Path: dir, Err: os.ErrNotExist}
 	}
+	return entry.Dir.Open()
+}
 
-	next := strings.Split(name, string(filepath.Separator))[0]
+// Turns a name given to Open into the form used in the index: a
+// slash-separated path relative to the directory, as accepted by
+// fs.ValidPath, whatever the host OS.  The rules are the same for every
+// entry point:
+//
+//   - a single leading slash is dropped, so "/a/b" and "a/b" are the same;
+//   - empty and "." segments are dropped, so "a//./b/" is "a/b";
+//   - ".." removes the segment before it, but never climbs above the root:
+//     "a/../b" is "b" while "../b" and "a/../../b" are fs.ErrInvalid;
+//   - backslashes and NUL bytes are fs.ErrInvalid rather than separators or
+//     terminators, so a name can't mean different things on different hosts;
+//     so is anything that isn't UTF-8.
+//
+// The empty path and the root come out as ".".  Names that are already in
+// this form are returned as they are, without allocating.
+func cleanPath(op, name string) (string, error) {
+	clean := name
+	if len(clean) > 0 && clean[0] == '/' {
+		clean = clean[1:]
+	}
+	if clean == "" {
+		return ".", nil
+	}
+	if strings.IndexByte(clean, '\\') >= 0 || strings.IndexByte(clean, 0) >= 0 {
+		return "", &os.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	if fs.ValidPath(clean) {
+		return clean, nil
+	}
+	if !utf8.ValidString(clean) {
+		return "", &os.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
 
-	if dir, exists := d.stat.dirs[next]; exists {
-		if dirHandle, err := dir.Open(); err == nil {
-			if p, err := filepath.Rel(next, name); err == nil {
-				return dirHandle.Open(p)
+	segments := make([]string, 0, strings.Count(clean, "/")+1)
+	for _, segment := range strings.Split(clean, "/") {
+		switch segment {
+		case "", ".":
+		case "..":
+			if len(segments) == 0 {
+				return "", &os.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
 			}
+			segments = segments[:len(segments)-1]
+		default:
+			segments = append(segments, segment)
 		}
-		return
 	}
-	if file, exists := d.stat.files[next]; exists {
-		h := &fileHandle{
-			stat: file,
-			open: true,
-		}
-		if file.Compressed {
-			h.inflater, err = zlib.NewReader(bytes.NewBuffer(h.stat.Data))
-		}
-		handle = h
-		return
+	if len(segments) == 0 {
+		return ".", nil
 	}
+	return strings.Join(segments, "/"), nil
+}
 
-	err = errors.New("not found: " + name)
-	return
+type _dirHandle struct {
+	stat *EmbedDir
+	dirReader
 }
 
-func (d *_dirHandle) Readdir(count int) ([]os.FileInfo, error) {
+// Reads a sorted listing through a directory handle, the way os.File does.
+type dirReader struct {
+	offset int
+	files  []os.FileInfo // may be shared between handles; never modified
+}
+
+// Reads the directory like os.File.Readdir: with count > 0, returns up to
+// count entries following those already read, and io.EOF once there are
+// none left; otherwise returns all remaining entries and a nil error.
+// Entries are sorted by name.  Results are copied, since callers such as
+// http.FileServer sort them in place and the listing is shared by every
+// handle.
+func (d *dirReader) Readdir(count int) ([]os.FileInfo, error) {
+	remaining := d.files[d.offset:]
 	if count <= 0 {
-		return d.files, nil
+		d.offset = len(d.files)
+		return append([]os.FileInfo{}, remaining...), nil
 	}
-	if d.offset >= len(d.files) {
+	if len(remaining) == 0 {
 		return []os.FileInfo{}, io.EOF
 	}
-	if d.offset+count > len(d.files) {
-		count = len(d.files) - d.offset
+	if count > len(remaining) {
+		count = len(remaining)
 	}
-	result := d.files[d.offset : d.offset+count]
 	d.offset += count
+	return append([]os.FileInfo(nil), remaining[:count]...), nil
+}
 
-	var err error
-	if d.offset > len(d.files) {
-		err = io.EOF
+// Reads the directory like os.File.ReadDir, sharing the position with
+// Readdir.
+func (d *dirReader) ReadDir(count int) ([]fs.DirEntry, error) {
+	infos, err := d.Readdir(count)
+	entries := make([]fs.DirEntry, len(infos))
+	for i, info := range infos {
+		entries[i] = fs.FileInfoToDirEntry(info)
 	}
-	return result, err
+	return entries, err
 }
 
-func (d *_dirHandle) Close() error {
-	return nil
-}
-func (d *_dirHandle) Read(p []byte) (int, error) {
+func (d *dirReader) Read(p []byte) (int, error) {
 	return 0, errors.New("not file")
 }
-func (d *_dirHandle) Seek(int64, int) (int64, error) {
-	return 0, os.ErrInvalid
+
+// Only rewinding is supported, which restarts Readdir from the first entry.
+func (d *dirReader) Seek(offset int64, whence int) (int64, error) {
+	if offset != 0 || whence != io.SeekStart {
+		return 0, os.ErrInvalid
+	}
+	d.offset = 0
+	return 0, nil
+}
+
+func (d *_dirHandle) Open(name string) (handle http.File, err error) {
+	clean, err := cleanPath("open", name)
+	if err != nil {
+		return
+	}
+	if clean == "." {
+		// A new handle, so that reading the directory through it doesn't
+		// move this one along.
+		return d.stat.Open()
+	}
+
+	entry, exists := d.stat.lookup(clean)
+	if !exists {
+		err = &os.PathError{Op: "open", Path: name, Err: os.ErrNotExist}
+		return
+	}
+	if entry.Dir != nil {
+		return entry.Dir.Open()
+	}
+
+	content, err := entry.File.content()
+	if err != nil {
+		return
+	}
+	h := &fileHandle{
+		stat: entry.File,
+		open: true,
+	}
+	h.reader.Reset(content)
+	handle = h
+	return
+}
+
+func (d *_dirHandle) Close() error {
+	return nil
 }
 func (d *_dirHandle) Stat() (os.FileInfo, error) {
 	return d.stat, nil
@@ -190,20 +341,31 @@ func (d *_dirHandle) Stat() (os.FileInfo, error) {
 ////////////////////////////////////////////////////////////////////////
 // REGULAR FILE
 
+// Compression codecs recorded in EmbedFile.Codec.
+const (
+	CodecNone    = ""
+	CodecZlib    = "zlib"
+	CodecDeflate = "deflate" // raw deflate, no header
+	CodecGzip    = "gzip"
+	CodecLZW     = "lzw" // LSB order, 8-bit literals
+)
+
+// An embedded file.  Data is a string so that the generated constants stay
+// in the read-only data of the binary rather than being copied to the heap.
 type EmbedFile struct {
-	FileName         string
-	Original         string
-	Compressed       bool
-	Data             []byte
-	OriginalSize     int64
-	ModificationTime time.Time
+	FileName        string
+	Original        string
+	Compressed      bool
+	Codec           string
+	Data            string
+	OriginalSize    int64
+	ModTimeUnixNano int64
 }
 
 type fileHandle struct {
-	stat     *EmbedFile
-	offset   int64
-	open     bool
-	inflater io.ReadCloser
+	stat   *EmbedFile
+	open   bool
+	reader strings.Reader // over Data, or the cached decompressed contents
 }
 
 func (f *EmbedFile) Name() string {
@@ -219,7 +381,7 @@ func (f *EmbedFile) Mode() os.FileMode {
 }
 
 func (f *EmbedFile) ModTime() time.Time {
-	return f.ModificationTime
+	return time.Unix(0, f.ModTimeUnixNano)
 }
 
 func (f *EmbedFile) IsDir() bool {
@@ -230,13 +392,95 @@ func (f *EmbedFile) Sys() interface{} {
 	return nil
 }
 
+// Returns the contents without copying.  Uncompressed files return Data
+// itself, which lives in read-only memory; compressed files return the
+// cached decompressed contents.  Either way the slice must not be modified.
+func (f *EmbedFile) Bytes() ([]byte, error) {
+	content, err := f.content()
+	if err != nil {
+		return nil, err
+	}
+	return unsafeBytes(content), nil
+}
+
+// Returns the data as stored, compressed with the returned codec, without
+// copying, so that it can be passed on still compressed.  The slice must not
+// be modified.
+func (f *EmbedFile) Stored() (codec string, data []byte) {
+	return f.codec(), unsafeBytes(f.Data)
+}
+
+// Files generated before codecs were recorded only set Compressed, and are
+// zlib.
+func (f *EmbedFile) codec() string {
+	if f.Codec == CodecNone && f.Compressed {
+		return CodecZlib
+	}
+	return f.Codec
+}
+
+// Returns the decompressed contents, from the cache when possible.
+func (f *EmbedFile) content() (string, error) {
+	if f.codec() == CodecNone {
+		return f.Data, nil
+	}
+	if content, cached := contents.get(f); cached {
+		return content, nil
+	}
+	data, err := f.inflate()
+	if err != nil {
+		return "", err
+	}
+	// Nothing writes to data from here on, so it can back a string.
+	content := unsafeString(data)
+	contents.put(f, content)
+	return content, nil
+}
+
+// Decompresses Data with the file's codec, using a pooled decompressor.
+func (f *EmbedFile) inflate() ([]byte, error) {
+	codec := f.codec()
+	pool, known := inflaters[codec]
+	if !known {
+		return nil, errors.New("unknown codec: " + codec)
+	}
+	dec, err := pool.reset(pool.Get(), strings.NewReader(f.Data))
+	if err != nil {
+		return nil, err
+	}
+	data := make([]byte, f.OriginalSize)
+	_, err = io.ReadFull(dec, data)
+	if err == nil {
+		// Reading on to the end checks the stream's trailer, its checksum
+		// and length, and that it holds no more than OriginalSize.
+		var extra [1]byte
+		switch _, err = io.ReadFull(dec, extra[:]); err {
+		case io.EOF:
+			err = nil
+		case nil:
+			err = errors.New("more than " + strconv.FormatInt(f.OriginalSize, 10) + " bytes of " + codec + " data")
+		}
+	}
+	pool.Put(dec)
+	if err != nil {
+		return nil, &os.PathError{Op: "inflate", Path: f.FileName, Err: err}
+	}
+	return data, nil
+}
+
+// Once closed, the handle fails like a closed *os.File, with os.ErrClosed.
 func (h *fileHandle) Close() error {
-	if h.inflater != nil {
-		return h.inflater.Close()
+	if !h.open {
+		return h.closed("close")
 	}
+	h.open = false
 	return nil
 }
 
+func (h *fileHandle) closed(op string) error {
+	return &os.PathError{Op: op, Path: h.stat.FileName, Err: os.ErrClosed}
+}
+
 func (h *fileHandle) Stat() (os.FileInfo, error) {
 	return h.stat, nil
 }
@@ -246,31 +490,146 @@ func (h *fileHandle) Readdir(count int) ([]os.FileInfo, error) {
 }
 
 func (h *fileHandle) Read(buff []byte) (int, error) {
-	if h.inflater != nil {
-		return h.inflater.Read(buff)
-	} else {
-		if h.offset >= int64(len(h.stat.Data)) {
-			return 0, io.EOF
-		}
-		n := copy(buff, h.stat.Data[h.offset:])
-		h.offset += int64(n)
-		return n, nil
+	if !h.open {
+		return 0, h.closed("read")
+	}
+	return h.reader.Read(buff)
+}
+
+func (h *fileHandle) ReadAt(buff []byte, offset int64) (int, error) {
+	if !h.open {
+		return 0, h.closed("read")
 	}
+	return h.reader.ReadAt(buff, offset)
+}
+
+// Writes the rest of the file without an intermediate buffer; writers that
+// implement io.StringWriter get the contents without any copying.
+func (h *fileHandle) WriteTo(w io.Writer) (int64, error) {
+	if !h.open {
+		return 0, h.closed("read")
+	}
+	return h.reader.WriteTo(w)
 }
 
 func (h *fileHandle) Seek(offset int64, whence int) (int64, error) {
-	switch whence {
-	case os.SEEK_SET:
-		h.offset = offset
-	case os.SEEK_CUR:
-		h.offset += offset
-	case os.SEEK_END:
-		h.offset = h.stat.OriginalSize + offset
-	default:
-		return 0, os.ErrInvalid
+	if !h.open {
+		return 0, h.closed("seek")
 	}
-	if h.offset < 0 {
-		h.offset = 0
+	return h.reader.Seek(offset, whence)
+}
+
+////////////////////////////////////////////////////////////////////////
+// DECOMPRESSED CONTENT CACHE
+
+// Default memory budget, in bytes, for decompressed contents.
+const DefaultCacheLimit = 32 << 20
+
+var contents = &contentCache{
+	limit:   DefaultCacheLimit,
+	entries: make(map[*EmbedFile]*list.Element),
+	lru:     list.New(),
+}
+
+// Sets the memory budget, in bytes, for decompressed contents kept between
+// opens of compressed files, evicting the least recently used contents as
+// needed.  0 disables the cache.
+func SetCacheLimit(limit int64) {
+	contents.Lock()
+	contents.limit = limit
+	contents.evict()
+	contents.Unlock()
+}
+
+// An LRU cache of decompressed contents bounded by their total size.
+type contentCache struct {
+	sync.Mutex
+	limit   int64
+	size    int64
+	entries map[*EmbedFile]*list.Element
+	lru     *list.List // of *cached, most recently used first
+}
+
+type cached struct {
+	file    *EmbedFile
+	content string
+}
+
+func (c *contentCache) get(f *EmbedFile) (string, bool) {
+	c.Lock()
+	defer c.Unlock()
+	if e, exists := c.entries[f]; exists {
+		c.lru.MoveToFront(e)
+		return e.Value.(*cached).content, true
+	}
+	return "", false
+}
+
+// Contents bigger than the whole budget are not kept.
+func (c *contentCache) put(f *EmbedFile, content string) {
+	c.Lock()
+	defer c.Unlock()
+	if _, exists := c.entries[f]; exists || int64(len(content)) > c.limit {
+		return
 	}
-	return h.offset, nil
+	c.entries[f] = c.lru.PushFront(&cached{file: f, content: content})
+	c.size += int64(len(content))
+	c.evict()
+}
+
+func (c *contentCache) evict() {
+	for c.size > c.limit {
+		e := c.lru.Back()
+		entry := c.lru.Remove(e).(*cached)
+		delete(c.entries, entry.file)
+		c.size -= int64(len(entry.content))
+	}
+}
+
+// Pools of decompressors, keyed by codec.  reset readies a pooled
+// decompressor, or a new one when the pool is empty, to read from r.
+type inflaterPool struct {
+	sync.Pool
+	reset func(dec interface{}, r io.Reader) (io.Reader, error)
+}
+
+var inflaters = map[string]*inflaterPool{
+	CodecZlib: {reset: func(dec interface{}, r io.Reader) (io.Reader, error) {
+		if z, ok := dec.(zlib.Resetter); ok {
+			return dec.(io.Reader), z.Reset(r, nil)
+		}
+		return zlib.NewReader(r)
+	}},
+	CodecDeflate: {reset: func(dec interface{}, r io.Reader) (io.Reader, error) {
+		if f, ok := dec.(flate.Resetter); ok {
+			return dec.(io.Reader), f.Reset(r, nil)
+		}
+		return flate.NewReader(r), nil
+	}},
+	CodecGzip: {reset: func(dec interface{}, r io.Reader) (io.Reader, error) {
+		if gz, ok := dec.(*gzip.Reader); ok {
+			return gz, gz.Reset(r)
+		}
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return gz, nil
+	}},
+	CodecLZW: {reset: func(dec interface{}, r io.Reader) (io.Reader, error) {
+		if l, ok := dec.(*lzw.Reader); ok {
+			l.Reset(r, lzw.LSB, 8)
+			return l, nil
+		}
+		return lzw.NewReader(r, lzw.LSB, 8), nil
+	}},
+}
+
+// Conversions between immutable byte slices and strings, without copying.
+func unsafeString(b []byte) string {
+	return unsafe.String(unsafe.SliceData(b), len(b))
+}
+
+func unsafeBytes(s string) []byte {
+	return unsafe.Slice(unsafe.StringData(s), len(s))
 }
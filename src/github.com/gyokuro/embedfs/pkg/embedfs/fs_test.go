@@ -0,0 +1,544 @@
+package embedfs
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"testing"
+	"unsafe"
+)
+
+type byEntryPath []Entry
+
+func (s byEntryPath) Len() int           { return len(s) }
+func (s byEntryPath) Less(i, j int) bool { return s[i].Path < s[j].Path }
+func (s byEntryPath) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+// Builds a tree the way the generator lays it out: every directory indexes
+// every path below it.  Each directory holds files files and, above the
+// given depth, fanout subdirectories.
+func buildTree(name string, depth, fanout, files int) *EmbedDir {
+	dir := &EmbedDir{DirName: name}
+	for i := 0; i < files; i++ {
+		file := fmt.Sprintf("file%d.txt", i)
+		dir.Entries = append(dir.Entries, Entry{
+			Path: file,
+			File: &EmbedFile{FileName: file, Data: file, OriginalSize: int64(len(file))},
+		})
+	}
+	if depth > 0 {
+		for i := 0; i < fanout; i++ {
+			sub := buildTree(fmt.Sprintf("dir%d", i), depth-1, fanout, files)
+			dir.Entries = append(dir.Entries, Entry{Path: sub.DirName, Dir: sub})
+			for _, e := range sub.Entries {
+				e.Path = sub.DirName + "/" + e.Path
+				dir.Entries = append(dir.Entries, e)
+			}
+		}
+	}
+	sort.Sort(byEntryPath(dir.Entries))
+	return dir
+}
+
+// Path of the last file in the deepest, last directory of the tree.
+func deepPath(depth, fanout, files int) string {
+	segments := make([]string, 0, depth+1)
+	for i := 0; i < depth; i++ {
+		segments = append(segments, fmt.Sprintf("dir%d", fanout-1))
+	}
+	segments = append(segments, fmt.Sprintf("file%d.txt", files-1))
+	return strings.Join(segments, "/")
+}
+
+func mountTree(t testing.TB, dir *EmbedDir) http.FileSystem {
+	handle, err := dir.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return handle
+}
+
+func TestOpenDeep(t *testing.T) {
+	fsys := mountTree(t, buildTree("root", 4, 3, 5))
+	name := deepPath(4, 3, 5)
+	for _, p := range []string{name, "/" + name, "./" + name, "dir0/../" + name} {
+		f, err := fsys.Open(p)
+		if err != nil {
+			t.Fatalf("open %s: %s", p, err)
+		}
+		stat, _ := f.Stat()
+		if stat.Name() != "file4.txt" {
+			t.Errorf("open %s: got %s", p, stat.Name())
+		}
+	}
+	if _, err := fsys.Open("dir2/dir2/nosuchfile"); err == nil {
+		t.Error("Expecting error opening non existent file.")
+	}
+}
+
+func TestLookupDoesNotAllocate(t *testing.T) {
+	root := buildTree("root", 5, 3, 5)
+	name := deepPath(5, 3, 5)
+	allocs := testing.AllocsPerRun(100, func() {
+		if _, exists := root.lookup(name); !exists {
+			t.Fatal("not found: ", name)
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("lookup allocates %v times", allocs)
+	}
+}
+
+func TestCleanPath(t *testing.T) {
+	for _, c := range []struct {
+		name, want string
+	}{
+		{"", "."},
+		{".", "."},
+		{"/", "."},
+		{"./", "."},
+		{"a", "a"},
+		{"/a/b", "a/b"},
+		{"a/b/", "a/b"},
+		{"a//b", "a/b"},
+		{"//a", "a"},
+		{"a/./b", "a/b"},
+		{"a/../b", "b"},
+		{"a/b/../../c", "c"},
+		{"a/..", "."},
+		{"...", "..."},
+		{"a/..b", "a/..b"},
+	} {
+		got, err := cleanPath("open", c.name)
+		if err != nil || got != c.want {
+			t.Errorf("cleanPath(%q) = %q, %v; want %q", c.name, got, err, c.want)
+		}
+	}
+	for _, name := range []string{
+		"..",
+		"/..",
+		"../a",
+		"a/../..",
+		"a/../../b",
+		"a\\b",
+		"..\\a",
+		"C:\\a",
+		"a\x00b",
+		"a/b\x00",
+		"a/\xff",
+	} {
+		if got, err := cleanPath("open", name); !errors.Is(err, fs.ErrInvalid) {
+			t.Errorf("cleanPath(%q) = %q, %v; want fs.ErrInvalid", name, got, err)
+		}
+	}
+}
+
+func TestOpenRejectsEscapes(t *testing.T) {
+	root := buildTree("root", 2, 2, 2)
+	fsys := mountTree(t, root)
+	for _, name := range []string{"../file0.txt", "dir0/../../file0.txt", "dir0\\file0.txt", "file0.txt\x00"} {
+		if _, err := fsys.Open(name); !errors.Is(err, fs.ErrInvalid) {
+			t.Errorf("open %q: got %v, want fs.ErrInvalid", name, err)
+		}
+		if _, err := root.Bytes(name); !errors.Is(err, fs.ErrInvalid) {
+			t.Errorf("bytes %q: got %v, want fs.ErrInvalid", name, err)
+		}
+		if _, err := root.Sub(name); !errors.Is(err, fs.ErrInvalid) {
+			t.Errorf("sub %q: got %v, want fs.ErrInvalid", name, err)
+		}
+	}
+	if _, err := fsys.Open("dir0/nosuchfile"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("open dir0/nosuchfile: got %v, want fs.ErrNotExist", err)
+	}
+
+	sub, err := root.Sub("/dir1/./dir0/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sub.Open("file1.txt"); err != nil {
+		t.Error(err)
+	}
+	if _, err := sub.Open("../file1.txt"); !errors.Is(err, fs.ErrInvalid) {
+		t.Errorf("sub can climb out of its root: %v", err)
+	}
+}
+
+func TestCleanPathDoesNotAllocate(t *testing.T) {
+	name := "/" + deepPath(5, 3, 5)
+	allocs := testing.AllocsPerRun(100, func() {
+		if _, err := cleanPath("open", name); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("cleanPath allocates %v times", allocs)
+	}
+}
+
+// Whatever it's given, cleanPath either rejects the name or returns a path
+// that fs.ValidPath accepts, that cleans to itself, and that doesn't climb
+// out of the root.  Names fs.ValidPath already accepts come back unchanged.
+func FuzzCleanPath(f *testing.F) {
+	for _, seed := range []string{
+		"", ".", "/", "a", "/a/b", "a//b/", "a/./b", "a/../b", "../a", "a/../..",
+		"a\\b", "a\x00b", "...", "a/..b", "//..//", "é/ü", "\xe2",
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, name string) {
+		got, err := cleanPath("open", name)
+		if err != nil {
+			if !errors.Is(err, fs.ErrInvalid) {
+				t.Fatalf("cleanPath(%q): unexpected error %v", name, err)
+			}
+			return
+		}
+		if !fs.ValidPath(got) {
+			t.Fatalf("cleanPath(%q) = %q, not a valid path", name, got)
+		}
+		if again, err := cleanPath("open", got); err != nil || again != got {
+			t.Fatalf("cleanPath(%q) = %q, %v; not idempotent", got, again, err)
+		}
+		if fs.ValidPath(name) && got != name {
+			t.Fatalf("cleanPath(%q) = %q; want valid path unchanged", name, got)
+		}
+		if strings.ContainsAny(got, "\\\x00") {
+			t.Fatalf("cleanPath(%q) = %q", name, got)
+		}
+	})
+}
+
+func benchmarkOpen(b *testing.B, depth int) {
+	const fanout, files = 3, 10
+	fsys := mountTree(b, buildTree("root", depth, fanout, files))
+	name := deepPath(depth, fanout, files)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		f, err := fsys.Open(name)
+		if err != nil {
+			b.Fatal(err)
+		}
+		f.Close()
+	}
+}
+
+func BenchmarkOpenDepth1(b *testing.B) { benchmarkOpen(b, 1) }
+func BenchmarkOpenDepth3(b *testing.B) { benchmarkOpen(b, 3) }
+func BenchmarkOpenDepth6(b *testing.B) { benchmarkOpen(b, 6) }
+
+func BenchmarkLookupDepth6(b *testing.B) {
+	root := buildTree("root", 6, 3, 10)
+	name := deepPath(6, 3, 10)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		root.lookup(name)
+	}
+}
+
+func BenchmarkOpenDirDepth6(b *testing.B) {
+	fsys := mountTree(b, buildTree("root", 6, 3, 10))
+	name := deepPath(6, 3, 10)
+	name = name[:strings.LastIndex(name, "/")]
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		f, err := fsys.Open(name)
+		if err != nil {
+			b.Fatal(err)
+		}
+		f.Close()
+	}
+}
+
+func compressedFile(t testing.TB, name string, codec string, data []byte) *EmbedFile {
+	zb, _, err := compress(bytes.NewReader(data), codec, flate.DefaultCompression)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &EmbedFile{
+		FileName:     name,
+		Compressed:   true,
+		Codec:        codec,
+		Data:         string(zb),
+		OriginalSize: int64(len(data)),
+	}
+}
+
+func TestCompressedSeekAndReadAt(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789abcdef"), 1000)
+	for _, codec := range []string{CodecZlib, CodecDeflate, CodecGzip, CodecLZW} {
+		file := compressedFile(t, "data.txt", codec, data)
+		fsys := mountTree(t, &EmbedDir{DirName: "root", Entries: []Entry{{Path: "data.txt", File: file}}})
+		f, err := fsys.Open("data.txt")
+		if err != nil {
+			t.Fatal(codec, err)
+		}
+		// What http.ServeContent does to sniff the content type.
+		head := make([]byte, 512)
+		io.ReadFull(f, head)
+		if _, err = f.Seek(0, io.SeekStart); err != nil {
+			t.Fatal(codec, err)
+		}
+		all, err := ioutil.ReadAll(f)
+		if err != nil || !bytes.Equal(all, data) {
+			t.Errorf("%s: contents differ after seek, err %v", codec, err)
+		}
+		buf := make([]byte, 16)
+		if _, err = f.(io.ReaderAt).ReadAt(buf, 32); err != nil || string(buf) != "0123456789abcdef" {
+			t.Errorf("%s: ReadAt got %q, err %v", codec, buf, err)
+		}
+	}
+}
+
+func TestBytesDoesNotCopy(t *testing.T) {
+	file := &EmbedFile{FileName: "a.txt", Data: "hello", OriginalSize: 5}
+	dir := &EmbedDir{DirName: "root", Entries: []Entry{{Path: "a.txt", File: file}}}
+	b, err := dir.Bytes("a.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if &b[0] != unsafe.StringData(file.Data) {
+		t.Error("Bytes copied uncompressed data")
+	}
+	c, _ := dir.ReadFile("/a.txt")
+	if string(c) != "hello" || &c[0] == &b[0] {
+		t.Error("ReadFile should return a copy")
+	}
+	if _, err = dir.Bytes("b.txt"); !os.IsNotExist(err) {
+		t.Error("Expecting not exist error, got", err)
+	}
+
+	var out bytes.Buffer
+	f, _ := mountTree(t, dir).Open("a.txt")
+	if n, err := f.(io.WriterTo).WriteTo(&out); n != 5 || err != nil || out.String() != "hello" {
+		t.Errorf("WriteTo wrote %d %q, err %v", n, out.String(), err)
+	}
+}
+
+func TestCacheLimit(t *testing.T) {
+	defer SetCacheLimit(DefaultCacheLimit)
+	SetCacheLimit(3000)
+
+	data := bytes.Repeat([]byte("x"), 1000)
+	files := make([]*EmbedFile, 4)
+	for i := range files {
+		files[i] = compressedFile(t, fmt.Sprint(i), CodecZlib, data)
+		if _, err := files[i].Bytes(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, cached := contents.get(files[0]); cached {
+		t.Error("Least recently used contents should have been evicted")
+	}
+	for _, f := range files[1:] {
+		if _, cached := contents.get(f); !cached {
+			t.Error("Expecting contents to be cached")
+		}
+	}
+	if contents.size > 3000 {
+		t.Errorf("Cache holds %d bytes, over its limit", contents.size)
+	}
+
+	big := compressedFile(t, "big", CodecZlib, bytes.Repeat(data, 4))
+	if b, err := big.Bytes(); err != nil || len(b) != 4000 {
+		t.Fatal("Contents over the limit should still be readable", err)
+	}
+	if _, cached := contents.get(big); cached {
+		t.Error("Contents over the limit should not be cached")
+	}
+}
+
+func names(infos []os.FileInfo) string {
+	list := make([]string, len(infos))
+	for i, info := range infos {
+		list[i] = info.Name()
+	}
+	return strings.Join(list, ",")
+}
+
+// A directory "root" holding a subdirectory also called "root", and files
+// whose names sort differently as full paths than as base names.
+func readdirTree() *EmbedDir {
+	sub := &EmbedDir{DirName: "root", Entries: []Entry{
+		{Path: "inner.txt", File: &EmbedFile{FileName: "inner.txt"}},
+	}}
+	dir := &EmbedDir{DirName: "root"}
+	for _, name := range []string{"c.txt", "a.txt", "b-c.txt", "b.txt"} {
+		dir.Entries = append(dir.Entries, Entry{Path: name, File: &EmbedFile{FileName: name}})
+	}
+	dir.Entries = append(dir.Entries,
+		Entry{Path: "root", Dir: sub},
+		Entry{Path: "root/inner.txt", File: sub.Entries[0].File})
+	sort.Sort(byEntryPath(dir.Entries))
+	return dir
+}
+
+func TestReaddirSortedAndComplete(t *testing.T) {
+	f, err := mountTree(t, readdirTree()).Open(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	list, err := f.Readdir(-1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := names(list); got != "a.txt,b-c.txt,b.txt,c.txt,root" {
+		t.Errorf("Unexpected listing %s", got)
+	}
+	// Everything has been read.
+	if list, err = f.Readdir(0); err != nil || len(list) != 0 {
+		t.Errorf("Expecting empty listing and no error, got %d, %v", len(list), err)
+	}
+	if list, err = f.Readdir(1); err != io.EOF || len(list) != 0 {
+		t.Errorf("Expecting io.EOF, got %d, %v", len(list), err)
+	}
+}
+
+func TestReaddirPaging(t *testing.T) {
+	f, _ := mountTree(t, readdirTree()).Open("/")
+	var pages []string
+	for {
+		list, err := f.Readdir(2)
+		if err == io.EOF {
+			if len(list) != 0 {
+				t.Error("Expecting no entries with io.EOF")
+			}
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		pages = append(pages, names(list))
+	}
+	if got := strings.Join(pages, "|"); got != "a.txt,b-c.txt|b.txt,c.txt|root" {
+		t.Errorf("Unexpected pages %s", got)
+	}
+
+	// Rewinding starts over; the rest of the listing follows a partial read.
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	f.Readdir(3)
+	if rest, err := f.Readdir(-1); err != nil || names(rest) != "c.txt,root" {
+		t.Errorf("Expecting the remaining entries, got %s, %v", names(rest), err)
+	}
+}
+
+func TestReadDir(t *testing.T) {
+	f, _ := mountTree(t, readdirTree()).Open(".")
+	entries, err := f.(fs.ReadDirFile).ReadDir(4)
+	if err != nil || len(entries) != 4 {
+		t.Fatalf("Expecting 4 entries, got %d, %v", len(entries), err)
+	}
+	entries, err = f.(fs.ReadDirFile).ReadDir(4)
+	if err != nil || len(entries) != 1 || entries[0].Name() != "root" || !entries[0].IsDir() {
+		t.Fatalf("Expecting the subdirectory last, got %v, %v", entries, err)
+	}
+	if _, err = f.(fs.ReadDirFile).ReadDir(4); err != io.EOF {
+		t.Errorf("Expecting io.EOF, got %v", err)
+	}
+}
+
+func TestOpenReturnsIndependentHandles(t *testing.T) {
+	fsys := mountTree(t, readdirTree())
+	first, _ := fsys.Open(".")
+	first.Readdir(-1)
+	second, _ := fsys.Open(".")
+	if list, _ := second.Readdir(-1); len(list) != 5 {
+		t.Errorf("A new handle should list every entry, got %s", names(list))
+	}
+
+	sub, err := fsys.Open("root")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if list, _ := sub.Readdir(-1); names(list) != "inner.txt" {
+		t.Errorf("Unexpected listing of root/root: %s", names(list))
+	}
+}
+
+func TestRequireFormat(t *testing.T) {
+	if !RequireFormat(FormatVersion) {
+		t.Error("current format refused")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("no panic for another format")
+		}
+	}()
+	RequireFormat(FormatVersion + 1)
+}
+
+func TestTemplateDigest(t *testing.T) {
+	source, err := ioutil.ReadFile("fs.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if digest := fmt.Sprintf("%x", sha256.Sum256(source)); digest != TemplateDigest {
+		t.Errorf("fs.go has changed since the embedded copy was made (digest %s, recorded %s): run embedfs bootstrap",
+			digest, TemplateDigest)
+	}
+}
+
+// Streams are read to the end, so that a bad trailer or extra data fails
+// instead of being served, and cached.
+func TestInflateChecksStream(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789abcdef"), 1000)
+	for _, c := range []struct {
+		codec  string
+		damage func(file *EmbedFile)
+	}{
+		{CodecZlib, func(f *EmbedFile) { f.Data = f.Data[:len(f.Data)-2] }},                          // truncated checksum
+		{CodecZlib, func(f *EmbedFile) { f.Data = f.Data[:len(f.Data)-1] + "\x00" }},                 // wrong checksum
+		{CodecGzip, func(f *EmbedFile) { f.Data = f.Data[:len(f.Data)-5] + "\x00\x00\x00\x00\x00" }}, // wrong CRC and size
+		{CodecGzip, func(f *EmbedFile) { f.OriginalSize-- }},                                         // longer than recorded
+		{CodecDeflate, func(f *EmbedFile) { f.OriginalSize-- }},
+		{CodecLZW, func(f *EmbedFile) { f.OriginalSize-- }},
+	} {
+		file := compressedFile(t, "data.txt", c.codec, data)
+		c.damage(file)
+		dir := &EmbedDir{DirName: "root", Entries: []Entry{{Path: "data.txt", File: file}}}
+		for i := 0; i < 2; i++ {
+			if b, err := dir.Bytes("data.txt"); err == nil {
+				t.Errorf("%s: read %d bytes of a damaged stream", c.codec, len(b))
+			}
+		}
+		if _, cached := contents.get(file); cached {
+			t.Errorf("%s: damaged contents cached", c.codec)
+		}
+	}
+}
+
+func TestClosedFile(t *testing.T) {
+	fsys := mountTree(t, buildTree("root", 0, 0, 1))
+	f, err := fsys.Open("file0.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 4)
+	_, readErr := f.Read(buf)
+	_, readAtErr := f.(io.ReaderAt).ReadAt(buf, 0)
+	_, seekErr := f.Seek(0, io.SeekStart)
+	_, writeToErr := f.(io.WriterTo).WriteTo(ioutil.Discard)
+	for op, err := range map[string]error{
+		"Read": readErr, "ReadAt": readAtErr, "Seek": seekErr, "WriteTo": writeToErr, "Close": f.Close(),
+	} {
+		if !errors.Is(err, os.ErrClosed) {
+			t.Errorf("%s after Close: got %v, want os.ErrClosed", op, err)
+		}
+	}
+}
@@ -0,0 +1,298 @@
+package embedfs
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Returned, wrapped, by Namespace.Mount when a mount would hide another
+// mount or something already served.
+var ErrMountConflict = errors.New("mount conflict")
+
+var _ http.FileSystem = (*Namespace)(nil)
+var _ http.File = (*namespaceDir)(nil)
+var _ fs.ReadDirFile = (*namespaceDir)(nil)
+
+// File systems mounted at virtual paths.  A name resolves in the mount with
+// the longest prefix of it; directories leading to mount points exist even
+// if nothing is mounted there, and mount points show up in the listings of
+// the directories holding them:
+//
+//	ns := embedfs.NewNamespace()
+//	ns.Mount("/", site.Mount())
+//	ns.Mount("/docs", docs.Mount())
+//	ns.Mount("/vendor/bootstrap", bootstrap.Mount())
+//	http.Handle("/", http.FileServer(ns))
+type Namespace struct {
+	lock   sync.RWMutex
+	mounts map[string]http.FileSystem // keyed by cleaned prefix
+}
+
+func NewNamespace() *Namespace {
+	return &Namespace{mounts: make(map[string]http.FileSystem)}
+}
+
+// Mounts a file system at the given prefix.  Fails with ErrMountConflict if
+// something is already mounted there, if the mount enclosing the prefix
+// already has something at that path, or if the file system has something
+// at the path of a mount below the prefix.
+func (n *Namespace) Mount(prefix string, fsys http.FileSystem) error {
+	clean, err := cleanPath("mount", prefix)
+	if err != nil {
+		return err
+	}
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	if _, exists := n.mounts[clean]; exists {
+		return fmt.Errorf("mounting %s: %w: already mounted", prefix, ErrMountConflict)
+	}
+	if clean != "." {
+		if outer, parent, found := n.resolve(path.Dir(clean)); found {
+			shadowed, err := exists(parent, relative(clean, outer))
+			if err != nil {
+				return err
+			}
+			if shadowed {
+				return fmt.Errorf("mounting %s: %w: shadows %s in the mount at %s",
+					prefix, ErrMountConflict, relative(clean, outer), outer)
+			}
+		}
+	}
+	for inner := range n.mounts {
+		if !below(inner, clean) {
+			continue
+		}
+		shadowed, err := exists(fsys, relative(inner, clean))
+		if err != nil {
+			return err
+		}
+		if shadowed {
+			return fmt.Errorf("mounting %s: %w: %s is shadowed by the mount at %s",
+				prefix, ErrMountConflict, relative(inner, clean), inner)
+		}
+	}
+	n.mounts[clean] = fsys
+	return nil
+}
+
+func (n *Namespace) Open(name string) (http.File, error) {
+	clean, err := cleanPath("open", name)
+	if err != nil {
+		return nil, err
+	}
+	n.lock.RLock()
+	defer n.lock.RUnlock()
+
+	children := n.children(clean)
+	var handle http.File
+	if prefix, fsys, found := n.resolve(clean); found {
+		handle, err = fsys.Open(relative(clean, prefix))
+		switch {
+		case err == nil:
+		case errors.Is(err, fs.ErrNotExist) && len(children) > 0:
+			handle = nil
+		default:
+			return nil, err
+		}
+	}
+	if len(children) == 0 && clean != "." {
+		if handle == nil {
+			return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrNotExist}
+		}
+		return handle, nil
+	}
+
+	// A directory holding mount points, or the root.
+	d := &namespaceDir{handle: handle}
+	if handle != nil {
+		stat, err := handle.Stat()
+		switch {
+		case err != nil:
+			handle.Close()
+			return nil, err
+		case !stat.IsDir():
+			// Mounts below it take precedence over a file.
+			handle.Close()
+			d.handle = nil
+		case clean != ".":
+			d.stat = renamedInfo{stat, path.Base(clean)}
+		default:
+			d.stat = stat
+		}
+	}
+	if d.stat == nil {
+		d.stat = n.stat(clean)
+	}
+	for _, child := range children {
+		d.mounted = append(d.mounted, n.stat(path.Join(clean, child)))
+	}
+	return d, nil
+}
+
+// Finds the mount with the longest prefix of the name.
+func (n *Namespace) resolve(clean string) (prefix string, fsys http.FileSystem, found bool) {
+	for prefix = clean; ; prefix = path.Dir(prefix) {
+		if fsys, found = n.mounts[prefix]; found || prefix == "." {
+			return
+		}
+	}
+}
+
+// Names in the directory that are mount points or lead to them, sorted.
+func (n *Namespace) children(clean string) []string {
+	seen := make(map[string]bool)
+	for prefix := range n.mounts {
+		if !below(prefix, clean) {
+			continue
+		}
+		rest := prefix
+		if clean != "." {
+			rest = prefix[len(clean)+1:]
+		}
+		if i := strings.IndexByte(rest, '/'); i >= 0 {
+			rest = rest[:i]
+		}
+		seen[rest] = true
+	}
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Describes a directory in the namespace itself: the root of what's mounted
+// there, renamed after the mount point, or a directory leading to mounts.
+func (n *Namespace) stat(clean string) os.FileInfo {
+	name := path.Base(clean)
+	if fsys, mounted := n.mounts[clean]; mounted {
+		if root, err := fsys.Open("/"); err == nil {
+			defer root.Close()
+			if stat, err := root.Stat(); err == nil {
+				return renamedInfo{stat, name}
+			}
+		}
+	}
+	return syntheticDir(name)
+}
+
+// Whether a cleaned path lies strictly below a cleaned directory.
+func below(name, dir string) bool {
+	if dir == "." {
+		return name != "."
+	}
+	return strings.HasPrefix(name, dir) && len(name) > len(dir) && name[len(dir)] == '/'
+}
+
+// The name to open, in the file system mounted at prefix, for a cleaned
+// path at or below it.
+func relative(clean, prefix string) string {
+	switch {
+	case clean == prefix:
+		return "/"
+	case prefix == ".":
+		return "/" + clean
+	}
+	return clean[len(prefix):]
+}
+
+type renamedInfo struct {
+	os.FileInfo
+	name string
+}
+
+func (r renamedInfo) Name() string {
+	return r.name
+}
+
+// A directory that exists only because there are mounts below it.
+type syntheticDir string
+
+func (d syntheticDir) Name() string {
+	return string(d)
+}
+func (d syntheticDir) Size() int64 {
+	return 0
+}
+func (d syntheticDir) Mode() os.FileMode {
+	return 0444 | os.ModeDir
+}
+func (d syntheticDir) ModTime() time.Time {
+	return time.Time{}
+}
+func (d syntheticDir) IsDir() bool {
+	return true
+}
+func (d syntheticDir) Sys() interface{} {
+	return nil
+}
+
+// A directory in the namespace holding mount points: whatever the enclosing
+// mount has there, if anything, with the mount points on top.
+type namespaceDir struct {
+	stat    os.FileInfo
+	handle  http.File // nil if the enclosing mount has nothing here
+	mounted []os.FileInfo
+	listed  bool
+	dirReader
+}
+
+func (d *namespaceDir) list() error {
+	if d.listed {
+		return nil
+	}
+	d.files = append(d.files, d.mounted...)
+	if d.handle != nil {
+		infos, err := d.handle.Readdir(-1)
+		if err != nil {
+			return err
+		}
+		shadowed := make(map[string]bool, len(d.mounted))
+		for _, info := range d.mounted {
+			shadowed[info.Name()] = true
+		}
+		for _, info := range infos {
+			if !shadowed[info.Name()] {
+				d.files = append(d.files, info)
+			}
+		}
+	}
+	sort.Slice(d.files, func(i, j int) bool { return d.files[i].Name() < d.files[j].Name() })
+	d.listed = true
+	return nil
+}
+
+func (d *namespaceDir) Readdir(count int) ([]os.FileInfo, error) {
+	if err := d.list(); err != nil {
+		return nil, err
+	}
+	return d.dirReader.Readdir(count)
+}
+
+func (d *namespaceDir) ReadDir(count int) ([]fs.DirEntry, error) {
+	if err := d.list(); err != nil {
+		return nil, err
+	}
+	return d.dirReader.ReadDir(count)
+}
+
+func (d *namespaceDir) Stat() (os.FileInfo, error) {
+	return d.stat, nil
+}
+
+func (d *namespaceDir) Close() error {
+	if d.handle != nil {
+		return d.handle.Close()
+	}
+	return nil
+}
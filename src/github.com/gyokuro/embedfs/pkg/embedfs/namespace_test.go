@@ -0,0 +1,108 @@
+package embedfs
+
+import (
+	"errors"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNamespace(t *testing.T) {
+	ns := NewNamespace()
+	for prefix, tree := range map[string]*EmbedDir{
+		"/":                 buildTree("site", 1, 1, 2),
+		"/docs":             buildTree("documentation", 0, 0, 1),
+		"/vendor/js/jquery": buildTree("jquery", 0, 0, 1),
+	} {
+		if err := ns.Mount(prefix, mountTree(t, tree)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for name, want := range map[string]string{
+		"/":                "dir0,docs,file0.txt,file1.txt,vendor",
+		"/dir0":            "file0.txt,file1.txt",
+		"docs":             "file0.txt",
+		"/vendor/":         "js",
+		"vendor/js":        "jquery",
+		"vendor/js/jquery": "file0.txt",
+	} {
+		if got := listing(t, ns, name); got != want {
+			t.Errorf("%s lists %s, want %s", name, got, want)
+		}
+	}
+	for name, want := range map[string]string{
+		"/file1.txt":                 "file1.txt",
+		"dir0/file0.txt":             "file0.txt",
+		"/docs/file0.txt":            "file0.txt",
+		"vendor/js/jquery/file0.txt": "file0.txt",
+	} {
+		if got := readString(t, ns, name); got != want {
+			t.Errorf("%s: got %q, want %q", name, got, want)
+		}
+	}
+
+	f, err := ns.Open("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	infos, _ := f.Readdir(-1)
+	for _, info := range infos {
+		if info.Name() == "docs" && !info.IsDir() {
+			t.Error("mount point docs is not listed as a directory")
+		}
+	}
+	if stat, _ := f.Stat(); stat.Name() != "site" {
+		t.Errorf("root is named %s", stat.Name())
+	}
+	f, err = ns.Open("docs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stat, _ := f.Stat(); stat.Name() != "documentation" || !stat.IsDir() {
+		t.Errorf("docs stats as %s", stat.Name())
+	}
+
+	for _, name := range []string{"/vendor/css", "docs/file1.txt", "vendor/js/file0.txt"} {
+		if _, err := ns.Open(name); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("open %s: got %v, want fs.ErrNotExist", name, err)
+		}
+	}
+	if _, err := ns.Open("docs/../../etc"); !errors.Is(err, fs.ErrInvalid) {
+		t.Errorf("open docs/../../etc: got %v, want fs.ErrInvalid", err)
+	}
+
+	w := httptest.NewRecorder()
+	http.FileServer(ns).ServeHTTP(w, httptest.NewRequest("GET", "/vendor/js/", nil))
+	if !strings.Contains(w.Body.String(), `href="jquery/"`) {
+		t.Errorf("file server listing of /vendor/js/: %s", w.Body)
+	}
+}
+
+func TestNamespaceConflicts(t *testing.T) {
+	ns := NewNamespace()
+	if err := ns.Mount("/a", mountTree(t, buildTree("a", 1, 1, 1))); err != nil {
+		t.Fatal(err)
+	}
+	for prefix, tree := range map[string]*EmbedDir{
+		// Mounted already.
+		"/a/": buildTree("other", 0, 0, 1),
+		// Hides a/dir0 of the mount at /a.
+		"a/dir0": buildTree("dir0", 0, 0, 1),
+		// Has "a", which the mount at /a would hide.
+		"/": {DirName: "site", Entries: []Entry{{Path: "a", Dir: &EmbedDir{DirName: "a"}}}},
+	} {
+		if err := ns.Mount(prefix, mountTree(t, tree)); !errors.Is(err, ErrMountConflict) {
+			t.Errorf("mount %s: got %v, want ErrMountConflict", prefix, err)
+		}
+	}
+	// Nothing in the way.
+	if err := ns.Mount("/a/dir1", mountTree(t, buildTree("dir1", 0, 0, 1))); err != nil {
+		t.Error(err)
+	}
+	if err := ns.Mount("/", mountTree(t, readdirTree())); err != nil {
+		t.Error(err)
+	}
+}
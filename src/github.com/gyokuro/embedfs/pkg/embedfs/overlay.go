@@ -0,0 +1,286 @@
+package embedfs
+
+import (
+	"errors"
+	"io/fs"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Whiteouts.  A file named WhiteoutPrefix+name in a layer hides name in the
+// layers below it; a file named WhiteoutOpaque in a directory hides the whole
+// of that directory in the layers below.  Whiteouts themselves never show up
+// in listings and can't be opened.
+const (
+	WhiteoutPrefix = ".wh."
+	WhiteoutOpaque = ".wh..wh..opq"
+)
+
+var _ http.FileSystem = (*overlay)(nil)
+var _ http.File = (*overlayDir)(nil)
+var _ fs.ReadDirFile = (*overlayDir)(nil)
+
+// Stacks file systems, the first on top.  A path resolves to the file in the
+// topmost layer that has it; directories present in several layers are
+// merged, with entries in upper layers shadowing those of the same name
+// below.  A layer whose root doesn't exist, such as an http.Dir for an
+// override directory that was never created, is skipped.
+//
+// The layers can be anything serving http.FileSystem, including the trees
+// returned by the generated Mount() functions:
+//
+//	fsys := embedfs.Overlay(http.Dir("/etc/myapp/templates"), templates.Mount())
+func Overlay(layers ...http.FileSystem) http.FileSystem {
+	return &overlay{layers: append([]http.FileSystem(nil), layers...)}
+}
+
+// Overlay for fs.FS layers.
+func OverlayFS(layers ...fs.FS) fs.FS {
+	converted := make([]http.FileSystem, len(layers))
+	for i, layer := range layers {
+		converted[i] = http.FS(layer)
+	}
+	return &ioFS{Overlay(converted...)}
+}
+
+type overlay struct {
+	layers []http.FileSystem
+}
+
+// An open directory in one of the layers.
+type layerDir struct {
+	layer  int
+	handle http.File
+	stat   os.FileInfo
+}
+
+func closeAll(dirs []layerDir) {
+	for _, d := range dirs {
+		d.handle.Close()
+	}
+}
+
+// Resolves the path one segment at a time, narrowing down the layers that
+// contribute to each directory on the way, so that whiteouts and files
+// shadowing directories anywhere along the path are honoured.
+func (o *overlay) Open(name string) (http.File, error) {
+	clean, err := cleanPath("open", name)
+	if err != nil {
+		return nil, err
+	}
+	dirs, err := o.root()
+	if err != nil {
+		return nil, err
+	}
+	if clean == "." {
+		return newOverlayDir(dirs), nil
+	}
+
+	dirPath := ""
+	segments := strings.Split(clean, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, WhiteoutPrefix) {
+			closeAll(dirs)
+			return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrNotExist}
+		}
+		file, children, err := o.child(dirs, dirPath, segment)
+		closeAll(dirs)
+		if err != nil {
+			return nil, err
+		}
+		dirPath += "/" + segment
+		switch {
+		case file == nil:
+			dirs = children
+		case i == len(segments)-1:
+			return file, nil
+		default:
+			// A file where the rest of the path needs a directory.
+			file.Close()
+			return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrNotExist}
+		}
+	}
+	return newOverlayDir(dirs), nil
+}
+
+// Opens the root of every layer down to the first opaque one.
+func (o *overlay) root() ([]layerDir, error) {
+	var dirs []layerDir
+	for l, layer := range o.layers {
+		handle, err := layer.Open("/")
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			closeAll(dirs)
+			return nil, err
+		}
+		stat, err := handle.Stat()
+		if err != nil {
+			handle.Close()
+			closeAll(dirs)
+			return nil, err
+		}
+		dirs = append(dirs, layerDir{layer: l, handle: handle, stat: stat})
+		opaque, err := exists(layer, "/"+WhiteoutOpaque)
+		if err != nil {
+			closeAll(dirs)
+			return nil, err
+		}
+		if opaque {
+			break
+		}
+	}
+	if len(dirs) == 0 {
+		return nil, &os.PathError{Op: "open", Path: "/", Err: os.ErrNotExist}
+	}
+	return dirs, nil
+}
+
+// Looks up a name in a directory present in the given layers.  Returns the
+// topmost file of that name, or the layers in which it is a directory, top
+// first.
+func (o *overlay) child(dirs []layerDir, dirPath, name string) (file http.File, children []layerDir, err error) {
+	p := dirPath + "/" + name
+	for _, d := range dirs {
+		handle, err := o.layers[d.layer].Open(p)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			closeAll(children)
+			return nil, nil, err
+		}
+		if err == nil {
+			stat, err := handle.Stat()
+			if err != nil {
+				handle.Close()
+				closeAll(children)
+				return nil, nil, err
+			}
+			if !stat.IsDir() {
+				if len(children) == 0 {
+					return handle, nil, nil
+				}
+				// Shadowed by the directory above, and shadows those below.
+				handle.Close()
+				break
+			}
+			children = append(children, layerDir{layer: d.layer, handle: handle, stat: stat})
+			opaque, err := exists(o.layers[d.layer], p+"/"+WhiteoutOpaque)
+			if err != nil {
+				closeAll(children)
+				return nil, nil, err
+			}
+			if opaque {
+				break
+			}
+		}
+		whiteout, err := exists(o.layers[d.layer], dirPath+"/"+WhiteoutPrefix+name)
+		if err != nil {
+			closeAll(children)
+			return nil, nil, err
+		}
+		if whiteout {
+			break
+		}
+	}
+	if len(children) == 0 {
+		return nil, nil, &os.PathError{Op: "open", Path: p, Err: os.ErrNotExist}
+	}
+	return nil, children, nil
+}
+
+// Whether the name exists in the file system.
+func exists(fsys http.FileSystem, name string) (bool, error) {
+	handle, err := fsys.Open(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	handle.Close()
+	return true, nil
+}
+
+// A directory merged from the layers it appears in.  The listing is read
+// from the layers the first time it's asked for.
+type overlayDir struct {
+	dirs   []layerDir
+	listed bool
+	dirReader
+}
+
+func newOverlayDir(dirs []layerDir) *overlayDir {
+	return &overlayDir{dirs: dirs}
+}
+
+func (d *overlayDir) list() error {
+	if d.listed {
+		return nil
+	}
+	seen := make(map[string]bool)
+	hidden := make(map[string]bool)
+	for _, dir := range d.dirs {
+		infos, err := dir.handle.Readdir(-1)
+		if err != nil {
+			return err
+		}
+		whiteouts := []string{}
+		for _, info := range infos {
+			name := info.Name()
+			switch {
+			case name == WhiteoutOpaque:
+			case strings.HasPrefix(name, WhiteoutPrefix):
+				whiteouts = append(whiteouts, name[len(WhiteoutPrefix):])
+			case !seen[name] && !hidden[name]:
+				seen[name] = true
+				d.files = append(d.files, info)
+			}
+		}
+		// Whiteouts only hide what's below them.
+		for _, name := range whiteouts {
+			hidden[name] = true
+		}
+	}
+	sort.Slice(d.files, func(i, j int) bool { return d.files[i].Name() < d.files[j].Name() })
+	d.listed = true
+	return nil
+}
+
+func (d *overlayDir) Readdir(count int) ([]os.FileInfo, error) {
+	if err := d.list(); err != nil {
+		return nil, err
+	}
+	return d.dirReader.Readdir(count)
+}
+
+func (d *overlayDir) ReadDir(count int) ([]fs.DirEntry, error) {
+	if err := d.list(); err != nil {
+		return nil, err
+	}
+	return d.dirReader.ReadDir(count)
+}
+
+func (d *overlayDir) Stat() (os.FileInfo, error) {
+	return d.dirs[0].stat, nil
+}
+
+func (d *overlayDir) Close() error {
+	closeAll(d.dirs)
+	return nil
+}
+
+// Serves an http.FileSystem as an fs.FS, rejecting names fs.FS doesn't
+// allow.  Directories are expected to implement fs.ReadDirFile, as the ones
+// opened here do.
+type ioFS struct {
+	fsys http.FileSystem
+}
+
+func (f *ioFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	return f.fsys.Open(name)
+}
@@ -0,0 +1,134 @@
+package embedfs
+
+import (
+	"errors"
+	"io/fs"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+func readString(t *testing.T, fsys http.FileSystem, name string) string {
+	f, err := fsys.Open(name)
+	if err != nil {
+		t.Fatalf("open %s: %s", name, err)
+	}
+	defer f.Close()
+	data, err := ioutil.ReadAll(f)
+	if err != nil {
+		t.Fatalf("read %s: %s", name, err)
+	}
+	return string(data)
+}
+
+func listing(t *testing.T, fsys http.FileSystem, name string) string {
+	f, err := fsys.Open(name)
+	if err != nil {
+		t.Fatalf("open %s: %s", name, err)
+	}
+	defer f.Close()
+	infos, err := f.Readdir(-1)
+	if err != nil {
+		t.Fatalf("readdir %s: %s", name, err)
+	}
+	return names(infos)
+}
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestOverlayOnEmbedded(t *testing.T) {
+	upper, err := ioutil.TempDir("", "embedfs-overlay")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(upper)
+	writeFiles(t, upper, map[string]string{
+		"file0.txt":                  "override",
+		"new.txt":                    "new",
+		WhiteoutPrefix + "file1.txt": "",
+		"dir0/" + WhiteoutOpaque:     "",
+		"dir0/only.txt":              "only",
+		"dir1/extra.txt":             "extra",
+	})
+	fsys := Overlay(http.Dir(upper), mountTree(t, buildTree("root", 1, 2, 2)))
+
+	if got := listing(t, fsys, "/"); got != "dir0,dir1,file0.txt,new.txt" {
+		t.Errorf("root lists %s", got)
+	}
+	if got := listing(t, fsys, "dir0"); got != "only.txt" {
+		t.Errorf("opaque dir0 lists %s", got)
+	}
+	if got := listing(t, fsys, "/dir1/"); got != "extra.txt,file0.txt,file1.txt" {
+		t.Errorf("dir1 lists %s", got)
+	}
+	for name, want := range map[string]string{
+		"file0.txt":      "override",
+		"/new.txt":       "new",
+		"dir0/only.txt":  "only",
+		"dir1/extra.txt": "extra",
+		"dir1/file1.txt": "file1.txt",
+	} {
+		if got := readString(t, fsys, name); got != want {
+			t.Errorf("%s: got %q, want %q", name, got, want)
+		}
+	}
+	for _, name := range []string{"file1.txt", WhiteoutPrefix + "file1.txt", "dir0/file0.txt", "dir0/" + WhiteoutOpaque, "nosuchfile"} {
+		if _, err := fsys.Open(name); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("open %s: got %v, want fs.ErrNotExist", name, err)
+		}
+	}
+	if _, err := fsys.Open("../file0.txt"); !errors.Is(err, fs.ErrInvalid) {
+		t.Errorf("open ../file0.txt: got %v, want fs.ErrInvalid", err)
+	}
+}
+
+func TestOverlaySkipsMissingLayers(t *testing.T) {
+	fsys := Overlay(http.Dir("/nonexistent/embedfs-overlay"), mountTree(t, buildTree("root", 0, 0, 2)))
+	if got := readString(t, fsys, "file1.txt"); got != "file1.txt" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestOverlayFS(t *testing.T) {
+	upper := fstest.MapFS{
+		"a.txt":                       {Data: []byte("upper a")},
+		"shadow":                      {Data: []byte("a file over a directory")},
+		"dir/b.txt":                   {Data: []byte("upper b")},
+		"dir/" + WhiteoutPrefix + "c": {},
+	}
+	lower := fstest.MapFS{
+		"a.txt":      {Data: []byte("lower a")},
+		"shadow/x":   {Data: []byte("hidden")},
+		"dir/b.txt":  {Data: []byte("lower b")},
+		"dir/c":      {Data: []byte("hidden")},
+		"dir/d.txt":  {Data: []byte("lower d")},
+		"lower/e.md": {Data: []byte("lower e")},
+	}
+	fsys := OverlayFS(upper, lower)
+	if err := fstest.TestFS(fsys, "a.txt", "shadow", "dir/b.txt", "dir/d.txt", "lower/e.md"); err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]string{"a.txt": "upper a", "dir/b.txt": "upper b", "lower/e.md": "lower e"} {
+		if got, err := fs.ReadFile(fsys, name); err != nil || string(got) != want {
+			t.Errorf("%s: got %q, %v; want %q", name, got, err, want)
+		}
+	}
+	for _, name := range []string{"shadow/x", "dir/c"} {
+		if _, err := fsys.Open(name); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("open %s: got %v, want fs.ErrNotExist", name, err)
+		}
+	}
+}
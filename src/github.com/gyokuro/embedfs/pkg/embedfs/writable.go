@@ -0,0 +1,572 @@
+package embedfs
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	errIsDir    = errors.New("is a directory")
+	errNotEmpty = errors.New("directory not empty")
+	errReadOnly = errors.New("not open for writing")
+)
+
+var _ http.FileSystem = (*Writable)(nil)
+var _ http.File = (*WritableFile)(nil)
+var _ io.Writer = (*WritableFile)(nil)
+var _ io.ReaderAt = (*WritableFile)(nil)
+
+// A writable file system kept in memory over a read-only one, typically an
+// embedded tree, so that tests can patch a file or add a fixture on top of
+// the real assets.  Changes are made in an in-memory layer stacked on the
+// base with Overlay: a file of the base is copied up the first time it's
+// written, and removing one leaves a whiteout, so the base and the
+// EmbedFiles it serves are never modified.
+type Writable struct {
+	lock sync.Mutex // serialises changes
+	base http.FileSystem
+	mem  *memFS
+	view http.FileSystem
+}
+
+func NewWritable(base http.FileSystem) *Writable {
+	root := &memNode{
+		info:     memInfo{name: ".", mode: 0755 | os.ModeDir, modTime: time.Now()},
+		children: make(map[string]*memNode),
+	}
+	if stat, err := statName(base, "/"); err == nil {
+		root.info.name, root.info.mode, root.info.modTime = stat.Name(), stat.Mode(), stat.ModTime()
+	}
+	mem := &memFS{root: root, base: base}
+	return &Writable{base: base, mem: mem, view: Overlay(mem, base)}
+}
+
+func statName(fsys http.FileSystem, name string) (os.FileInfo, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return f.Stat()
+}
+
+// Opens the named file or directory for reading, with the changes made so
+// far.
+func (w *Writable) Open(name string) (http.File, error) {
+	return w.view.Open(name)
+}
+
+func (w *Writable) Stat(name string) (os.FileInfo, error) {
+	return statName(w.view, name)
+}
+
+// Creates or truncates the named file, like os.Create.
+func (w *Writable) Create(name string) (*WritableFile, error) {
+	return w.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+}
+
+// Opens the named file for writing, like os.OpenFile; flag must include
+// os.O_WRONLY or os.O_RDWR.  Use Open to read.
+func (w *Writable) OpenFile(name string, flag int, perm os.FileMode) (*WritableFile, error) {
+	clean, err := w.writablePath("open", name)
+	if err != nil {
+		return nil, err
+	}
+	if flag&(os.O_WRONLY|os.O_RDWR) == 0 {
+		return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrInvalid}
+	}
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	stat, err := w.Stat(clean)
+	switch {
+	case err != nil && !os.IsNotExist(err):
+		return nil, err
+	case err != nil && flag&os.O_CREATE == 0:
+		return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrNotExist}
+	case err != nil:
+		if err := w.checkParent("open", name, clean); err != nil {
+			return nil, err
+		}
+		stat = memInfo{mode: perm.Perm(), modTime: time.Now()}
+	case stat.IsDir():
+		return nil, &os.PathError{Op: "open", Path: name, Err: errIsDir}
+	case flag&(os.O_CREATE|os.O_EXCL) == os.O_CREATE|os.O_EXCL:
+		return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrExist}
+	}
+
+	node := w.mem.lookup(clean)
+	if node == nil {
+		// Copy up.
+		var data []byte
+		if flag&os.O_TRUNC == 0 && stat.Size() > 0 {
+			if data, err = w.readFile(clean); err != nil {
+				return nil, err
+			}
+		}
+		node = w.mem.create(clean, data, stat.Mode().Perm(), stat.ModTime())
+	} else if flag&os.O_TRUNC != 0 {
+		w.mem.truncate(node, 0)
+	}
+	return &WritableFile{
+		mem:      w.mem,
+		node:     node,
+		writable: true,
+		append:   flag&os.O_APPEND != 0,
+	}, nil
+}
+
+// Writes the named file, creating it if necessary, like os.WriteFile.
+func (w *Writable) WriteFile(name string, data []byte, perm os.FileMode) error {
+	f, err := w.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(data)
+	return err
+}
+
+// Changes the size of the named file, like os.Truncate.
+func (w *Writable) Truncate(name string, size int64) error {
+	f, err := w.OpenFile(name, os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return f.Truncate(size)
+}
+
+// Creates the named directory, like os.Mkdir.
+func (w *Writable) Mkdir(name string, perm os.FileMode) error {
+	clean, err := w.writablePath("mkdir", name)
+	if err != nil {
+		return err
+	}
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if _, err := w.Stat(clean); err == nil {
+		return &os.PathError{Op: "mkdir", Path: name, Err: os.ErrExist}
+	}
+	if err := w.checkParent("mkdir", name, clean); err != nil {
+		return err
+	}
+	w.mem.mkdir(clean, perm.Perm(), time.Now())
+	return nil
+}
+
+// Removes the named file or empty directory, like os.Remove.
+func (w *Writable) Remove(name string) error {
+	clean, err := w.writablePath("remove", name)
+	if err != nil {
+		return err
+	}
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	stat, err := w.Stat(clean)
+	if err != nil {
+		return err
+	}
+	if stat.IsDir() {
+		dir, err := w.view.Open(clean)
+		if err != nil {
+			return err
+		}
+		infos, err := dir.Readdir(1)
+		dir.Close()
+		if len(infos) > 0 {
+			return &os.PathError{Op: "remove", Path: name, Err: errNotEmpty}
+		}
+		if err != nil && err != io.EOF {
+			return err
+		}
+	}
+	return w.remove(clean)
+}
+
+// Renames a file or directory, like os.Rename, replacing any file already
+// at the new name.  Directories are copied up in full.
+func (w *Writable) Rename(oldName, newName string) error {
+	oldClean, err := w.writablePath("rename", oldName)
+	if err != nil {
+		return err
+	}
+	newClean, err := w.writablePath("rename", newName)
+	if err != nil {
+		return err
+	}
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if _, err := w.Stat(oldClean); err != nil {
+		return err
+	}
+	if oldClean == newClean {
+		return nil
+	}
+	if below(newClean, oldClean) {
+		return &os.PathError{Op: "rename", Path: newName, Err: os.ErrInvalid}
+	}
+	if stat, err := w.Stat(newClean); err == nil && stat.IsDir() {
+		return &os.PathError{Op: "rename", Path: newName, Err: os.ErrExist}
+	}
+	if err := w.checkParent("rename", newName, newClean); err != nil {
+		return err
+	}
+	if err := w.copy(oldClean, newClean); err != nil {
+		return err
+	}
+	return w.remove(oldClean)
+}
+
+// Cleans a name for changing, which can't be the root or a whiteout.
+func (w *Writable) writablePath(op, name string) (string, error) {
+	clean, err := cleanPath(op, name)
+	if err != nil {
+		return "", err
+	}
+	if clean == "." || strings.HasPrefix(path.Base(clean), WhiteoutPrefix) {
+		return "", &os.PathError{Op: op, Path: name, Err: os.ErrInvalid}
+	}
+	return clean, nil
+}
+
+func (w *Writable) checkParent(op, name, clean string) error {
+	stat, err := w.Stat(path.Dir(clean))
+	if err != nil || !stat.IsDir() {
+		return &os.PathError{Op: op, Path: name, Err: os.ErrNotExist}
+	}
+	return nil
+}
+
+func (w *Writable) readFile(clean string) ([]byte, error) {
+	f, err := w.view.Open(clean)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ioutil.ReadAll(f)
+}
+
+// Copies a file or directory, as currently seen, into the memory layer.
+func (w *Writable) copy(from, to string) error {
+	stat, err := w.Stat(from)
+	if err != nil {
+		return err
+	}
+	if !stat.IsDir() {
+		data, err := w.readFile(from)
+		if err != nil {
+			return err
+		}
+		w.mem.create(to, data, stat.Mode().Perm(), stat.ModTime())
+		return nil
+	}
+
+	w.mem.mkdir(to, stat.Mode().Perm(), stat.ModTime())
+	dir, err := w.view.Open(from)
+	if err != nil {
+		return err
+	}
+	infos, err := dir.Readdir(-1)
+	dir.Close()
+	if err != nil {
+		return err
+	}
+	for _, info := range infos {
+		if err := w.copy(from+"/"+info.Name(), to+"/"+info.Name()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Drops the name from the memory layer, hiding it in the base if it's there.
+func (w *Writable) remove(clean string) error {
+	w.mem.remove(clean)
+	inBase, err := exists(w.base, clean)
+	if err != nil {
+		return err
+	}
+	if inBase {
+		w.mem.create(path.Join(path.Dir(clean), WhiteoutPrefix+path.Base(clean)), nil, 0444, time.Now())
+	}
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////
+// MEMORY LAYER
+
+type memInfo struct {
+	name    string
+	size    int64
+	mode    os.FileMode
+	modTime time.Time
+}
+
+func (i memInfo) Name() string {
+	return i.name
+}
+func (i memInfo) Size() int64 {
+	return i.size
+}
+func (i memInfo) Mode() os.FileMode {
+	return i.mode
+}
+func (i memInfo) ModTime() time.Time {
+	return i.modTime
+}
+func (i memInfo) IsDir() bool {
+	return i.mode.IsDir()
+}
+func (i memInfo) Sys() interface{} {
+	return nil
+}
+
+type memNode struct {
+	info     memInfo // size is kept in data
+	data     []byte
+	children map[string]*memNode // nil for files
+}
+
+func (n *memNode) stat() memInfo {
+	info := n.info
+	info.size = int64(len(n.data))
+	return info
+}
+
+// The in-memory layer.  Directories of the base that hold changes are
+// mirrored here, with the mode and time of the base's, and whiteouts are
+// kept as ordinary empty files.
+type memFS struct {
+	lock sync.RWMutex
+	root *memNode
+	base http.FileSystem // mirrored
+}
+
+func (m *memFS) Open(name string) (http.File, error) {
+	clean, err := cleanPath("open", name)
+	if err != nil {
+		return nil, err
+	}
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	node := m.find(clean)
+	if node == nil {
+		return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrNotExist}
+	}
+	if node.children == nil {
+		return &WritableFile{mem: m, node: node}, nil
+	}
+	d := &memDir{stat: node.stat()}
+	for _, child := range node.children {
+		d.files = append(d.files, child.stat())
+	}
+	sort.Slice(d.files, func(i, j int) bool { return d.files[i].Name() < d.files[j].Name() })
+	return d, nil
+}
+
+func (m *memFS) find(clean string) *memNode {
+	node := m.root
+	if clean == "." {
+		return node
+	}
+	for _, segment := range strings.Split(clean, "/") {
+		if node = node.children[segment]; node == nil {
+			return nil
+		}
+	}
+	return node
+}
+
+func (m *memFS) lookup(clean string) *memNode {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	return m.find(clean)
+}
+
+// Returns the directory, mirroring it and its parents from the base if need
+// be.
+func (m *memFS) dir(clean string) *memNode {
+	node := m.root
+	if clean == "." {
+		return node
+	}
+	segments := strings.Split(clean, "/")
+	for i, segment := range segments {
+		child := node.children[segment]
+		if child == nil || child.children == nil {
+			info := memInfo{name: segment, mode: 0755 | os.ModeDir, modTime: time.Now()}
+			if stat, err := statName(m.base, strings.Join(segments[:i+1], "/")); err == nil && stat.IsDir() {
+				info.mode, info.modTime = stat.Mode(), stat.ModTime()
+			}
+			child = &memNode{info: info, children: make(map[string]*memNode)}
+			node.children[segment] = child
+		}
+		node = child
+	}
+	return node
+}
+
+func (m *memFS) mkdir(clean string, perm os.FileMode, modTime time.Time) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	parent, name := m.dir(path.Dir(clean)), path.Base(clean)
+	if child := parent.children[name]; child == nil || child.children == nil {
+		parent.children[name] = &memNode{
+			info:     memInfo{name: name, mode: perm | os.ModeDir, modTime: modTime},
+			children: make(map[string]*memNode),
+		}
+	}
+}
+
+func (m *memFS) create(clean string, data []byte, perm os.FileMode, modTime time.Time) *memNode {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	name := path.Base(clean)
+	node := &memNode{info: memInfo{name: name, mode: perm, modTime: modTime}, data: data}
+	m.dir(path.Dir(clean)).children[name] = node
+	return node
+}
+
+func (m *memFS) remove(clean string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if parent := m.find(path.Dir(clean)); parent != nil && parent.children != nil {
+		delete(parent.children, path.Base(clean))
+	}
+}
+
+func (m *memFS) truncate(node *memNode, size int64) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	node.resize(size)
+}
+
+func (n *memNode) resize(size int64) {
+	if size <= int64(len(n.data)) {
+		n.data = n.data[:size]
+	} else {
+		n.data = append(n.data, make([]byte, size-int64(len(n.data)))...)
+	}
+	n.info.modTime = time.Now()
+}
+
+type memDir struct {
+	stat memInfo
+	dirReader
+}
+
+func (d *memDir) Stat() (os.FileInfo, error) {
+	return d.stat, nil
+}
+
+func (d *memDir) Close() error {
+	return nil
+}
+
+// A file of the memory layer, opened for reading through Writable.Open or
+// for writing through Writable.OpenFile.  Writes are seen by every handle
+// on the file.
+type WritableFile struct {
+	mem      *memFS
+	node     *memNode
+	offset   int64
+	writable bool
+	append   bool
+}
+
+func (f *WritableFile) Read(buff []byte) (int, error) {
+	n, err := f.ReadAt(buff, f.offset)
+	f.offset += int64(n)
+	if err == io.EOF && n > 0 {
+		err = nil
+	}
+	return n, err
+}
+
+func (f *WritableFile) ReadAt(buff []byte, offset int64) (int, error) {
+	if offset < 0 {
+		return 0, os.ErrInvalid
+	}
+	f.mem.lock.RLock()
+	defer f.mem.lock.RUnlock()
+	if offset >= int64(len(f.node.data)) {
+		return 0, io.EOF
+	}
+	n := copy(buff, f.node.data[offset:])
+	if n < len(buff) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+func (f *WritableFile) Write(buff []byte) (int, error) {
+	if !f.writable {
+		return 0, errReadOnly
+	}
+	f.mem.lock.Lock()
+	defer f.mem.lock.Unlock()
+	if f.append {
+		f.offset = int64(len(f.node.data))
+	}
+	if end := f.offset + int64(len(buff)); end > int64(len(f.node.data)) {
+		f.node.resize(end)
+	}
+	n := copy(f.node.data[f.offset:], buff)
+	f.offset += int64(n)
+	f.node.info.modTime = time.Now()
+	return n, nil
+}
+
+func (f *WritableFile) Truncate(size int64) error {
+	if !f.writable {
+		return errReadOnly
+	}
+	if size < 0 {
+		return os.ErrInvalid
+	}
+	f.mem.truncate(f.node, size)
+	return nil
+}
+
+func (f *WritableFile) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekCurrent:
+		offset += f.offset
+	case io.SeekEnd:
+		f.mem.lock.RLock()
+		offset += int64(len(f.node.data))
+		f.mem.lock.RUnlock()
+	}
+	if offset < 0 {
+		return 0, os.ErrInvalid
+	}
+	f.offset = offset
+	return offset, nil
+}
+
+func (f *WritableFile) Stat() (os.FileInfo, error) {
+	f.mem.lock.RLock()
+	defer f.mem.lock.RUnlock()
+	return f.node.stat(), nil
+}
+
+func (f *WritableFile) Readdir(count int) ([]os.FileInfo, error) {
+	return nil, errors.New("not a directory")
+}
+
+func (f *WritableFile) Close() error {
+	return nil
+}
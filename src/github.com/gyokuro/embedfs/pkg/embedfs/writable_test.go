@@ -0,0 +1,185 @@
+package embedfs
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestWritable(t *testing.T) {
+	tree := buildTree("root", 1, 1, 2)
+	w := NewWritable(mountTree(t, tree))
+
+	if err := w.WriteFile("file0.txt", []byte("patched"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := w.OpenFile("dir0/file1.txt", os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	io.WriteString(f, "+more")
+	f.Close()
+	if err := w.Truncate("file1.txt", 4); err != nil {
+		t.Fatal(err)
+	}
+	if f, err = w.Create("/dir0/new.txt"); err != nil {
+		t.Fatal(err)
+	}
+	io.WriteString(f, "new")
+	if err := w.Mkdir("fixtures", 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteFile("fixtures/a.json", []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, want := range map[string]string{
+		"file0.txt":       "patched",
+		"file1.txt":       "file",
+		"dir0/file0.txt":  "file0.txt",
+		"dir0/file1.txt":  "file1.txt+more",
+		"dir0/new.txt":    "new",
+		"fixtures/a.json": "{}",
+	} {
+		if got := readString(t, w, name); got != want {
+			t.Errorf("%s: got %q, want %q", name, got, want)
+		}
+	}
+	if got := listing(t, w, "/"); got != "dir0,file0.txt,file1.txt,fixtures" {
+		t.Errorf("root lists %s", got)
+	}
+	if got := listing(t, w, "dir0"); got != "file0.txt,file1.txt,new.txt" {
+		t.Errorf("dir0 lists %s", got)
+	}
+	if stat, err := w.Stat("file1.txt"); err != nil || stat.Size() != 4 {
+		t.Errorf("file1.txt stats as %v, %v", stat, err)
+	}
+
+	// The embedded tree is left alone.
+	for _, e := range tree.Entries {
+		if e.File != nil && e.File.Data != e.File.FileName {
+			t.Errorf("%s changed to %q", e.Path, e.File.Data)
+		}
+	}
+}
+
+// Directories mirrored to hold changes keep the mode and time of the base's.
+func TestWritableMirroredDirs(t *testing.T) {
+	tree := buildTree("root", 2, 1, 1)
+	tree.ModTimeUnixNano = buildTime.UnixNano()
+	for _, e := range tree.Entries {
+		if e.Dir != nil {
+			e.Dir.ModTimeUnixNano = buildTime.Add(time.Duration(len(e.Path))).UnixNano()
+		}
+	}
+	w := NewWritable(mountTree(t, tree))
+	if err := w.WriteFile("dir0/dir0/new.txt", []byte("new"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	before := time.Now()
+	if err := w.Mkdir("dir0/fixtures", 0700); err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]time.Time{
+		"/":         buildTime,
+		"dir0":      buildTime.Add(4),
+		"dir0/dir0": buildTime.Add(9),
+	} {
+		if stat, err := w.Stat(name); err != nil || stat.Mode() != 0444|os.ModeDir || !stat.ModTime().Equal(want) {
+			t.Errorf("%s stats as %v, %v", name, stat, err)
+		}
+	}
+	if stat, err := w.Stat("dir0/fixtures"); err != nil || stat.Mode() != 0700|os.ModeDir || stat.ModTime().Before(before) {
+		t.Errorf("dir0/fixtures stats as %v, %v", stat, err)
+	}
+}
+
+func TestWritableRenameAndRemove(t *testing.T) {
+	w := NewWritable(mountTree(t, buildTree("root", 1, 1, 2)))
+
+	if err := w.Rename("dir0", "moved"); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Rename("file0.txt", "moved/renamed.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if got := listing(t, w, "/"); got != "file1.txt,moved" {
+		t.Errorf("root lists %s", got)
+	}
+	if got := listing(t, w, "moved"); got != "file0.txt,file1.txt,renamed.txt" {
+		t.Errorf("moved lists %s", got)
+	}
+	if got := readString(t, w, "moved/renamed.txt"); got != "file0.txt" {
+		t.Errorf("renamed.txt: got %q", got)
+	}
+	for _, name := range []string{"dir0", "dir0/file0.txt", "file0.txt"} {
+		if _, err := w.Open(name); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("open %s: got %v, want fs.ErrNotExist", name, err)
+		}
+	}
+
+	if err := w.Remove("moved"); !errors.Is(err, errNotEmpty) {
+		t.Errorf("removing a full directory: got %v", err)
+	}
+	for _, name := range []string{"moved/file0.txt", "moved/file1.txt", "moved/renamed.txt", "moved", "file1.txt"} {
+		if err := w.Remove(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := listing(t, w, "/"); got != "" {
+		t.Errorf("root lists %s", got)
+	}
+
+	// Names that were removed from the base can be used again.
+	if err := w.Mkdir("dir0", 0755); err != nil {
+		t.Fatal(err)
+	}
+	if got := listing(t, w, "dir0"); got != "" {
+		t.Errorf("recreated dir0 lists %s", got)
+	}
+	if err := w.WriteFile("file1.txt", []byte("again"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := readString(t, w, "file1.txt"); got != "again" {
+		t.Errorf("file1.txt: got %q", got)
+	}
+}
+
+func TestWritableErrors(t *testing.T) {
+	w := NewWritable(mountTree(t, buildTree("root", 1, 1, 2)))
+
+	if _, err := w.Create("nosuchdir/a.txt"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("create in a missing directory: got %v", err)
+	}
+	if err := w.Mkdir("dir0", 0755); !errors.Is(err, fs.ErrExist) {
+		t.Errorf("mkdir over a directory: got %v", err)
+	}
+	if _, err := w.OpenFile("file0.txt", os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644); !errors.Is(err, fs.ErrExist) {
+		t.Errorf("exclusive create of an existing file: got %v", err)
+	}
+	if _, err := w.OpenFile("file0.txt", os.O_RDONLY, 0); !errors.Is(err, fs.ErrInvalid) {
+		t.Errorf("open for reading: got %v", err)
+	}
+	if err := w.Rename("dir0", "dir0/inner"); !errors.Is(err, fs.ErrInvalid) {
+		t.Errorf("renaming a directory into itself: got %v", err)
+	}
+	for _, name := range []string{"/", "../file0.txt", WhiteoutPrefix + "file0.txt"} {
+		if err := w.WriteFile(name, nil, 0644); !errors.Is(err, fs.ErrInvalid) {
+			t.Errorf("write %q: got %v, want fs.ErrInvalid", name, err)
+		}
+	}
+
+	if err := w.WriteFile("file0.txt", []byte("patched"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := w.Open("file0.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.(io.Writer).Write([]byte("x")); err != errReadOnly {
+		t.Errorf("writing through Open: got %v", err)
+	}
+}
@@ -0,0 +1,317 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Returned, wrapped, by Generate when the files selected are over a budget
+// that isn't only a warning; the report lists the violations.
+var ErrOverBudget = errors.New("over budget")
+
+// What a budget limits.  Sizes are those of the files as given, before
+// compression.
+type BudgetKind int
+
+const (
+	BudgetTotal BudgetKind = iota // bytes of all the files
+	BudgetFile                    // bytes of each file
+	BudgetDir                     // bytes of the files directly in each directory
+	BudgetCount                   // number of files
+)
+
+var budgetKinds = []string{"total", "file", "dir", "files"}
+
+func (k BudgetKind) String() string {
+	if k < 0 || int(k) >= len(budgetKinds) {
+		return fmt.Sprintf("BudgetKind(%d)", int(k))
+	}
+	return budgetKinds[k]
+}
+
+func (k BudgetKind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// A limit, applied to the files or directories matching Pattern.  Patterns
+// without a slash are matched against base names, otherwise against whole
+// paths in the virtual tree, using path.Match syntax; an empty pattern
+// matches everything.  Only file and dir limits take a pattern.
+type BudgetRule struct {
+	Kind    BudgetKind `json:"kind"`
+	Pattern string     `json:"pattern,omitempty"`
+	Limit   int64      `json:"limit"` // bytes, or files for BudgetCount
+	Warn    bool       `json:"warn"`  // only warn when over the limit
+}
+
+func (r BudgetRule) String() string {
+	s := r.Kind.String()
+	if r.Warn {
+		s = "warn:" + s
+	}
+	if r.Pattern != "" {
+		s += ":" + r.Pattern
+	}
+	if r.Kind == BudgetCount {
+		return s + "=" + strconv.FormatInt(r.Limit, 10)
+	}
+	return s + "=" + FormatSize(r.Limit)
+}
+
+func (r BudgetRule) matches(name string) bool {
+	if r.Pattern == "" {
+		return true
+	}
+	if !strings.Contains(r.Pattern, "/") {
+		name = path.Base(name)
+	}
+	matched, _ := path.Match(r.Pattern, name)
+	return matched
+}
+
+// Parses a rule of the form [warn:]kind[:glob]=limit, e.g. "total=100M",
+// "warn:file=1M" or "file:*.mp4=50M".  Sizes take a K, M or G suffix for
+// KiB, MiB or GiB.
+func ParseBudgetRule(s string) (rule BudgetRule, err error) {
+	i := strings.LastIndex(s, "=")
+	if i < 0 {
+		return rule, errors.New("expected [warn:]kind[:glob]=limit, got " + s)
+	}
+	spec, limit := s[:i], s[i+1:]
+	if strings.HasPrefix(spec, "warn:") {
+		rule.Warn, spec = true, spec[len("warn:"):]
+	}
+	kind := spec
+	if j := strings.Index(spec, ":"); j >= 0 {
+		kind, rule.Pattern = spec[:j], spec[j+1:]
+	}
+	found := false
+	for k, name := range budgetKinds {
+		if kind == name {
+			rule.Kind, found = BudgetKind(k), true
+		}
+	}
+	switch {
+	case !found:
+		return rule, errors.New("unknown budget: " + kind)
+	case rule.Pattern != "" && rule.Kind != BudgetFile && rule.Kind != BudgetDir:
+		return rule, fmt.Errorf("%s budgets apply to the whole tree, not %s", kind, rule.Pattern)
+	}
+	if _, err := path.Match(rule.Pattern, ""); err != nil {
+		return rule, err
+	}
+	if rule.Kind == BudgetCount {
+		rule.Limit, err = strconv.ParseInt(limit, 10, 64)
+	} else {
+		rule.Limit, err = parseSize(limit)
+	}
+	if err == nil && rule.Limit < 0 {
+		err = errors.New("negative limit: " + limit)
+	}
+	return rule, err
+}
+
+func parseSize(s string) (int64, error) {
+	shift := uint(0)
+	switch {
+	case strings.HasSuffix(s, "K"):
+		shift = 10
+	case strings.HasSuffix(s, "M"):
+		shift = 20
+	case strings.HasSuffix(s, "G"):
+		shift = 30
+	}
+	if shift > 0 {
+		s = s[:len(s)-1]
+	}
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, errors.New("bad size: " + s)
+	}
+	return int64(n * float64(int64(1)<<shift)), nil
+}
+
+// Formats a byte count the way budgets are given, e.g. 1.5M.
+func FormatSize(n int64) string {
+	for _, unit := range []struct {
+		suffix string
+		shift  uint
+	}{{"G", 30}, {"M", 20}, {"K", 10}} {
+		if n >= 1<<unit.shift {
+			s := strconv.FormatFloat(float64(n)/float64(int64(1)<<unit.shift), 'f', 1, 64)
+			return strings.TrimSuffix(s, ".0") + unit.suffix
+		}
+	}
+	return strconv.FormatInt(n, 10)
+}
+
+// Limits on what is embedded, each a failure or only a warning.  For each
+// kind and threshold the first rule with a matching pattern applies, or
+// else the first without one, so that overrides can follow the defaults
+// they override.
+type Budgets struct {
+	Rules []BudgetRule
+}
+
+// Implements flag.Value so that rules can be given repeatedly on the
+// command line.
+func (b *Budgets) String() string {
+	if b == nil {
+		return ""
+	}
+	rules := make([]string, len(b.Rules))
+	for i, r := range b.Rules {
+		rules[i] = r.String()
+	}
+	return strings.Join(rules, ",")
+}
+
+func (b *Budgets) Set(s string) error {
+	rule, err := ParseBudgetRule(s)
+	if err != nil {
+		return err
+	}
+	b.Rules = append(b.Rules, rule)
+	return nil
+}
+
+// Returns the rule of the kind and threshold that applies to the name.
+func (b *Budgets) rule(kind BudgetKind, warn bool, name string) (BudgetRule, bool) {
+	var fallback *BudgetRule
+	for i, r := range b.Rules {
+		if r.Kind != kind || r.Warn != warn {
+			continue
+		}
+		if r.Pattern == "" {
+			if fallback == nil {
+				fallback = &b.Rules[i]
+			}
+		} else if r.matches(name) {
+			return r, true
+		}
+	}
+	if fallback == nil {
+		return BudgetRule{}, false
+	}
+	return *fallback, true
+}
+
+// How far something is over a budget.
+type BudgetViolation struct {
+	Rule      BudgetRule `json:"rule"`
+	Path      string     `json:"path,omitempty"`      // the file or directory over its limit; empty for the total and the count
+	Size      int64      `json:"size"`                // bytes, or files for BudgetCount
+	Offenders []Offender `json:"offenders,omitempty"` // the largest files making up Size, or the directories with the most files
+}
+
+type Offender struct {
+	Path string `json:"path"`
+	Size int64  `json:"size"` // bytes, or files
+}
+
+// Offenders listed for each violation.
+const maxOffenders = 5
+
+func (v BudgetViolation) String() string {
+	size := FormatSize(v.Size)
+	if v.Rule.Kind == BudgetCount {
+		size = strconv.FormatInt(v.Size, 10)
+	}
+	what := v.Rule.Kind.String()
+	if v.Path != "" {
+		what += " " + v.Path
+	}
+	return fmt.Sprintf("%s is %s, over the %s limit", what, size, v.Rule)
+}
+
+type budgetFile struct {
+	path string // in the virtual tree
+	size int64
+}
+
+// Checks the files against the budgets, failures before warnings and each
+// sorted by how far over the limit they are.  Something over both its
+// failure and its warning limit is only reported as a failure.
+func (b *Budgets) check(files []budgetFile) (violations []BudgetViolation) {
+	if b == nil || len(b.Rules) == 0 {
+		return nil
+	}
+	var total int64
+	dirs := make(map[string][]budgetFile)
+	for _, f := range files {
+		total += f.size
+		dirs[path.Dir(f.path)] = append(dirs[path.Dir(f.path)], f)
+	}
+	// Returns the rule broken, failure first.
+	over := func(kind BudgetKind, name string, size int64) (BudgetRule, bool) {
+		for _, warn := range []bool{false, true} {
+			if r, ok := b.rule(kind, warn, name); ok && size > r.Limit {
+				return r, true
+			}
+		}
+		return BudgetRule{}, false
+	}
+
+	if r, ok := over(BudgetTotal, "", total); ok {
+		violations = append(violations, BudgetViolation{Rule: r, Size: total, Offenders: largest(files)})
+	}
+	if r, ok := over(BudgetCount, "", int64(len(files))); ok {
+		counts := []budgetFile{}
+		for dir, in := range dirs {
+			counts = append(counts, budgetFile{dir, int64(len(in))})
+		}
+		violations = append(violations, BudgetViolation{Rule: r, Size: int64(len(files)), Offenders: largest(counts)})
+	}
+	for dir, in := range dirs {
+		var size int64
+		for _, f := range in {
+			size += f.size
+		}
+		if r, ok := over(BudgetDir, dir, size); ok {
+			violations = append(violations, BudgetViolation{Rule: r, Path: dir, Size: size, Offenders: largest(in)})
+		}
+	}
+	for _, f := range files {
+		if r, ok := over(BudgetFile, f.path, f.size); ok {
+			violations = append(violations, BudgetViolation{Rule: r, Path: f.path, Size: f.size})
+		}
+	}
+
+	sort.Slice(violations, func(i, j int) bool {
+		a, b := violations[i], violations[j]
+		if a.Rule.Warn != b.Rule.Warn {
+			return !a.Rule.Warn
+		}
+		if a.Rule.Kind != b.Rule.Kind {
+			return a.Rule.Kind < b.Rule.Kind
+		}
+		if a.Size-a.Rule.Limit != b.Size-b.Rule.Limit {
+			return a.Size-a.Rule.Limit > b.Size-b.Rule.Limit
+		}
+		return a.Path < b.Path
+	})
+	return violations
+}
+
+// Returns the largest, up to maxOffenders.
+func largest(files []budgetFile) []Offender {
+	sorted := append([]budgetFile(nil), files...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].size != sorted[j].size {
+			return sorted[i].size > sorted[j].size
+		}
+		return sorted[i].path < sorted[j].path
+	})
+	if len(sorted) > maxOffenders {
+		sorted = sorted[:maxOffenders]
+	}
+	offenders := make([]Offender, len(sorted))
+	for i, f := range sorted {
+		offenders[i] = Offender{Path: f.path, Size: f.size}
+	}
+	return offenders
+}
@@ -0,0 +1,109 @@
+package generator
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseBudgetRule(t *testing.T) {
+	for s, want := range map[string]BudgetRule{
+		"total=100M":        {Kind: BudgetTotal, Limit: 100 << 20},
+		"warn:file=1.5K":    {Kind: BudgetFile, Limit: 1536, Warn: true},
+		"file:*.mp4=2G":     {Kind: BudgetFile, Pattern: "*.mp4", Limit: 2 << 30},
+		"warn:dir:img/*=10": {Kind: BudgetDir, Pattern: "img/*", Limit: 10, Warn: true},
+		"files=300":         {Kind: BudgetCount, Limit: 300},
+	} {
+		rule, err := ParseBudgetRule(s)
+		if err != nil || rule != want {
+			t.Errorf("%s: got %+v, %v, want %+v", s, rule, err, want)
+		}
+		if again, _ := ParseBudgetRule(rule.String()); again != rule {
+			t.Errorf("%s: %s does not parse back", s, rule)
+		}
+	}
+	for _, s := range []string{"total", "size=1M", "total:*.png=1M", "file=lots", "files=1K", "file:[=1M", "dir=-1"} {
+		if _, err := ParseBudgetRule(s); err == nil {
+			t.Errorf("%s: parsed", s)
+		}
+	}
+}
+
+func TestBudgetsCheck(t *testing.T) {
+	files := []budgetFile{
+		{"site/index.html", 100},
+		{"site/img/a.png", 3000},
+		{"site/img/b.png", 2000},
+		{"site/img/c.png", 500},
+		{"site/video/intro.mp4", 50000},
+	}
+	budgets := &Budgets{}
+	for _, s := range []string{
+		"total=40K", "warn:total=10K",
+		"file=4K", "file:*.mp4=100K", "warn:file=1K",
+		"warn:dir=5K",
+		"files=4",
+	} {
+		if err := budgets.Set(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got := []string{}
+	for _, v := range budgets.check(files) {
+		line := v.String()
+		for _, o := range v.Offenders {
+			line += "; " + o.Path
+		}
+		got = append(got, line)
+	}
+	// Failures before warnings, each kind by how far over the limit.  The
+	// override for *.mp4 only raises the failure limit.
+	want := []string{
+		"total is 54.3K, over the total=40K limit; site/video/intro.mp4; site/img/a.png; site/img/b.png; site/img/c.png; site/index.html",
+		"files is 5, over the files=4 limit; site/img; site; site/video",
+		"file site/video/intro.mp4 is 48.8K, over the warn:file=1K limit",
+		"file site/img/a.png is 2.9K, over the warn:file=1K limit",
+		"file site/img/b.png is 2K, over the warn:file=1K limit",
+		"dir site/video is 48.8K, over the warn:dir=5K limit; site/video/intro.mp4",
+		"dir site/img is 5.4K, over the warn:dir=5K limit; site/img/a.png; site/img/b.png; site/img/c.png",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("violations:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestGenerateOverBudget(t *testing.T) {
+	root := generateSources(t)
+	defer os.RemoveAll(root)
+	budgets := &Budgets{}
+	budgets.Set("warn:file=1K")
+	opts := Options{
+		ImportRoot: "example.com/out",
+		Sources:    []Source{{Path: filepath.Join(root, "dist"), Virtual: "site"}},
+		Budgets:    budgets,
+		Output:     newMemOutput(),
+	}
+	report, err := Generate(context.Background(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Budget) != 1 || report.Budget[0].Path != "site/css/style.css" {
+		t.Errorf("warnings %v", report.Budget)
+	}
+
+	budgets.Set("file:*.css=10K")
+	opts.Output = newMemOutput()
+	report, err = Generate(context.Background(), opts)
+	if !errors.Is(err, ErrOverBudget) {
+		t.Fatalf("got %v, want ErrOverBudget", err)
+	}
+	if len(report.Budget) != 1 || report.Budget[0].Rule.Warn {
+		t.Errorf("violations %v", report.Budget)
+	}
+	if len(opts.Output.(*memOutput).files) != 0 {
+		t.Errorf("wrote %d files over budget", len(opts.Output.(*memOutput).files))
+	}
+}
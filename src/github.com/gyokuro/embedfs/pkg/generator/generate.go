@@ -0,0 +1,810 @@
+package generator
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/gyokuro/embedfs/pkg/embedfs"
+)
+
+// Import path of the runtime shared by generated packages, so that their
+// trees can be mixed: overlaid, mounted in one namespace, or served by one
+// ArchiveServer.  Generated code checks it reads their embedfs.FormatVersion.
+const RuntimePackage = "github.com/gyokuro/embedfs/pkg/embedfs"
+
+// Directory under DestDir of the package holding contents shared by several
+// files, when deduplicating.
+const blobsDir = "embedfs_blobs"
+
+// Returned, wrapped, by Generate when files of the virtual tree clash; the
+// report lists them.
+var ErrConflict = errors.New("conflicts in the virtual tree")
+
+// A directory or archive to embed.
+type Source struct {
+	Path    string // a directory, or a .zip, .tar, .tar.gz or .tgz archive
+	Virtual string // where its files go in the virtual tree; empty to mirror their paths, less Options.Strip components
+}
+
+// What Generate embeds and where it writes the generated packages.
+type Options struct {
+	DestDir    string   // where the generated packages go
+	ImportRoot string   // import path of DestDir; found from $GOPATH if empty
+	Sources    []Source // the current directory if empty
+	Strip      int      // leading path components dropped from the files of sources without a Virtual path
+	Root       string   // directory under DestDir holding the root of the virtual tree, for files at the top of it
+
+	Match       *regexp.Regexp             // files selected, by path on disk or name in the archive; nil selects all
+	Exclude     *regexp.Regexp             // files left out of those matched; nil leaves out none
+	Compression *embedfs.CompressionPolicy // nil uses DefaultCompressionPolicy
+	Workers     int                        // files translated in parallel; less than 1 means one
+	Budgets     *Budgets                   // limits checked before anything is written; nil for none
+
+	Gofmt     bool // run gofmt on the generated source
+	Dedup     bool // store contents shared by several files once, in the embedfs_blobs package
+	KeepNewer bool // leave generated files newer than their sources as they are
+	DryRun    bool // translate everything but write nothing
+
+	// The runtime generated code uses: the package at the import path
+	// RuntimePackage, such as the shared RuntimePackage, or else a copy of
+	// it in DestDir, written to generated-fs.go from Runtime unless nil.
+	RuntimePackage string
+	Runtime        []byte
+
+	Output Output      // where the files are written; nil writes to DestDir on disk
+	Logger *log.Logger // nil logs nothing
+}
+
+// Where Generate writes.  Names are slash-separated and relative to the
+// destination directory.
+type Output interface {
+	// Creates or replaces the file, and the directories holding it.
+	WriteFile(name string, data []byte) error
+	Stat(name string) (os.FileInfo, error)
+}
+
+// Implemented by outputs that can remove files, for Watch to remove those
+// generated from sources since removed, and Generate shared contents no
+// longer used; others keep them.
+type Remover interface {
+	Remove(name string) error
+}
+
+// Implemented by outputs that can list the files in a directory, for
+// Generate to remove shared contents no file uses any more; others keep
+// them.
+type Lister interface {
+	// Returns the names of the files in the directory, none if it does
+	// not exist.
+	ReadDir(name string) ([]string, error)
+}
+
+// Writes below a directory on disk.
+func DirOutput(root string) Output {
+	return dirOutput(root)
+}
+
+type dirOutput string
+
+func (d dirOutput) WriteFile(name string, data []byte) error {
+	target := filepath.Join(string(d), filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(target), 0777); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(target, data, 0644)
+}
+
+func (d dirOutput) Stat(name string) (os.FileInfo, error) {
+	return os.Stat(filepath.Join(string(d), filepath.FromSlash(name)))
+}
+
+func (d dirOutput) ReadDir(name string) ([]string, error) {
+	infos, err := ioutil.ReadDir(filepath.Join(string(d), filepath.FromSlash(name)))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	names := []string{}
+	for _, info := range infos {
+		if info.Mode().IsRegular() {
+			names = append(names, info.Name())
+		}
+	}
+	return names, err
+}
+
+func (d dirOutput) Remove(name string) error {
+	err := os.Remove(filepath.Join(string(d), filepath.FromSlash(name)))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// Writes nothing, for dry runs.
+type discardOutput struct{}
+
+func (discardOutput) WriteFile(name string, data []byte) error { return nil }
+
+func (discardOutput) Remove(name string) error { return nil }
+
+func (discardOutput) Stat(name string) (os.FileInfo, error) {
+	return nil, &os.PathError{Op: "stat", Path: name, Err: os.ErrNotExist}
+}
+
+// What Generate did; WriteJSON and WriteText format it.
+type Report struct {
+	Files     []FileReport      `json:"files"`     // files embedded, sorted by path
+	Skipped   []SkippedFile     `json:"skipped"`   // files of the sources not selected
+	Dirs      []DirReport       `json:"dirs"`      // directories of the virtual tree holding files, sorted
+	GoFiles   []string          `json:"goFiles"`   // generated files written, or not in a dry run, relative to DestDir, sorted
+	Removed   []string          `json:"removed"`   // generated files removed, their sources being gone or contents no longer shared
+	Conflicts []string          `json:"conflicts"` // why the files could not be embedded, if they couldn't
+	Budget    []BudgetViolation `json:"budget"`
+	Stages    []Stage           `json:"stages"` // in the order run
+	Saved     int64             `json:"saved"`  // bytes not stored again, the same contents being shared
+}
+
+type FileReport struct {
+	Source      string  `json:"source"` // path on disk, or name in the archive
+	Path        string  `json:"path"`   // in the virtual tree
+	Rule        string  `json:"rule"`   // why it was selected
+	GoFile      string  `json:"goFile"` // relative to DestDir
+	Package     string  `json:"package"`
+	Compression string  `json:"compression"` // the compression rule applied
+	Codec       string  `json:"codec"`
+	Size        int64   `json:"size"`
+	StoredSize  int64   `json:"storedSize"`
+	Ratio       float64 `json:"ratio"`          // StoredSize to Size, 1 for empty files
+	Blob        string  `json:"blob,omitempty"` // the constant in embedfs_blobs holding the contents, if shared
+	Unchanged   bool    `json:"unchanged"`      // left as it was, being newer than its source; nothing is stored then
+}
+
+type SkippedFile struct {
+	Source string `json:"source"`
+	Rule   string `json:"rule"` // why it was left out
+}
+
+// Totals of the files directly in a directory.
+type DirReport struct {
+	Path       string `json:"path"`
+	Files      int    `json:"files"`
+	Size       int64  `json:"size"`
+	StoredSize int64  `json:"storedSize"`
+}
+
+// How long a stage of Generate took: collect, check, translate, toc and
+// runtime.
+type Stage struct {
+	Name    string        `json:"name"`
+	Elapsed time.Duration `json:"elapsedNs"`
+}
+
+type generator struct {
+	opts          Options
+	output        Output
+	runtimeImport string
+	importRoot    string
+	lock          sync.Mutex
+	report        *Report
+}
+
+func (g *generator) logf(format string, args ...interface{}) {
+	if g.opts.Logger != nil {
+		g.opts.Logger.Printf(format, args...)
+	}
+}
+
+// Writes a generated file, through gofmt if asked.
+func (g *generator) write(name string, data []byte) error {
+	return g.writeFile(name, data, g.opts.Gofmt)
+}
+
+func (g *generator) writeFile(name string, data []byte, format bool) error {
+	if format {
+		formatted, err := gofmt(name, data)
+		if err != nil {
+			return fmt.Errorf("gofmt %s: %w", name, err)
+		}
+		data = formatted
+	}
+	if err := g.output.WriteFile(name, data); err != nil {
+		return err
+	}
+	g.lock.Lock()
+	g.report.GoFiles = append(g.report.GoFiles, name)
+	g.lock.Unlock()
+	return nil
+}
+
+// Generates the Go packages embedding the files selected from the sources:
+// one package for each directory of the virtual tree, holding a file for
+// each file in it and the TOC indexing everything below it.
+func Generate(ctx context.Context, opts Options) (Report, error) {
+	report, _, err := generate(ctx, opts, nil, nil)
+	return report, err
+}
+
+// Generates everything if previous is nil.  Otherwise generates only the
+// files not in the previous tree or whose sources, by path on disk or
+// archive, are among those changed, removes the files no longer in the tree,
+// and writes the TOCs of the directories holding any of them; the report
+// only holds those files.  Returns the tree generated.
+func generate(ctx context.Context, opts Options, previous virtualTree, changed map[string]bool) (Report, virtualTree, error) {
+	report := &Report{Files: []FileReport{}, Skipped: []SkippedFile{}, Dirs: []DirReport{}, GoFiles: []string{},
+		Removed: []string{}, Conflicts: []string{}, Budget: []BudgetViolation{}, Stages: []Stage{}}
+	g := &generator{opts: opts, output: opts.Output, report: report}
+	if g.output == nil {
+		g.output = DirOutput(opts.DestDir)
+	}
+	if opts.DryRun {
+		g.output = discardOutput{}
+	}
+	if g.opts.Compression == nil {
+		g.opts.Compression = embedfs.DefaultCompressionPolicy()
+	}
+	if len(opts.Sources) == 0 {
+		g.opts.Sources = []Source{{Path: "."}}
+	}
+	importRoot := opts.ImportRoot
+	if importRoot == "" {
+		destDirAbs, err := filepath.Abs(opts.DestDir)
+		if err != nil {
+			return *report, nil, err
+		}
+		if importRoot, err = CheckGoPath(destDirAbs); err != nil {
+			return *report, nil, fmt.Errorf("%s not reachable in $GOPATH: %w", opts.DestDir, err)
+		}
+	}
+	g.logf("Import root: %s", importRoot)
+	g.importRoot = importRoot
+	g.runtimeImport = opts.RuntimePackage
+	if g.runtimeImport == "" {
+		g.runtimeImport = importRoot
+	}
+
+	start := time.Now()
+	stage := func(name string) {
+		now := time.Now()
+		report.Stages = append(report.Stages, Stage{Name: name, Elapsed: now.Sub(start)})
+		start = now
+	}
+
+	virtual, err := g.collect(ctx)
+	if err != nil {
+		return *report, nil, err
+	}
+	stage("collect")
+	filesByDirectory := virtual.byDirectory()
+	report.Conflicts = append(report.Conflicts, virtual.conflicts(filesByDirectory)...)
+	for name := range virtual {
+		if opts.Dedup && strings.HasPrefix(name, blobsDir+"/") {
+			report.Conflicts = append(report.Conflicts, blobsDir+": reserved for contents shared by several files")
+			break
+		}
+	}
+	if len(report.Conflicts) > 0 {
+		sort.Strings(report.Conflicts)
+		return *report, nil, fmt.Errorf("%w: %d", ErrConflict, len(report.Conflicts))
+	}
+	err = g.checkBudgets(ctx, virtual)
+	stage("check")
+	if err != nil {
+		return *report, nil, err
+	}
+
+	// One unit for each file, in the package of its directory
+	units := []translator{}
+	leaves := []*translationUnit{}
+	for dir, files := range filesByDirectory {
+		packageName := Sanitize(dir)
+		for _, file := range files {
+			name := path.Join(filepath.ToSlash(dir), file)
+			src := virtual[name]
+			if old, existed := previous[name]; existed && old.origin == src.origin && old.path == src.path &&
+				!changed[src.origin] {
+				src.blob = old.blob
+				virtual[name] = src
+				continue
+			}
+			u := newTranslationUnit(g, packageName, src.fsys, src.path, file, filepath.ToSlash(dir))
+			units = append(units, u)
+			leaves = append(leaves, u)
+		}
+	}
+	if err := translateAll(ctx, units, opts.Workers); err != nil {
+		return *report, nil, err
+	}
+	if opts.Dedup {
+		emitters, err := g.share(leaves)
+		if err != nil {
+			return *report, nil, err
+		}
+		if err := translateAll(ctx, emitters, opts.Workers); err != nil {
+			return *report, nil, err
+		}
+	}
+	stage("translate")
+	dirTotals := make(map[string]*DirReport)
+	for _, u := range leaves {
+		name := path.Join(path.Dir(u.gofile), u.baseName)
+		src := virtual[name]
+		src.blob = u.blob
+		virtual[name] = src
+		f := FileReport{
+			Source:      u.src,
+			Path:        name,
+			Rule:        virtual[name].rule,
+			GoFile:      u.gofile,
+			Package:     u.packageName,
+			Compression: g.opts.Compression.Rule(u.src).String(),
+			Codec:       u.codec,
+			Size:        u.fileInfo.Size(),
+			StoredSize:  int64(len(u.data)),
+			Ratio:       1,
+			Blob:        u.blob,
+			Unchanged:   u.unchanged,
+		}
+		if f.Size > 0 && !f.Unchanged {
+			f.Ratio = float64(f.StoredSize) / float64(f.Size)
+		}
+		report.Files = append(report.Files, f)
+
+		dir := dirTotals[path.Dir(name)]
+		if dir == nil {
+			dir = &DirReport{Path: path.Dir(name)}
+			dirTotals[dir.Path] = dir
+		}
+		dir.Files++
+		dir.Size += f.Size
+		dir.StoredSize += f.StoredSize
+	}
+	sort.Slice(report.Files, func(i, j int) bool { return report.Files[i].Path < report.Files[j].Path })
+	for _, dir := range dirTotals {
+		report.Dirs = append(report.Dirs, *dir)
+	}
+	sort.Slice(report.Dirs, func(i, j int) bool { return report.Dirs[i].Path < report.Dirs[j].Path })
+
+	// A TOC for every directory holding files, and those between them;
+	// those of directories no longer holding any are removed
+	dirs := make(map[string]bool)
+	for dir := range filesByDirectory {
+		for p := dir; p != "."; p = filepath.Dir(p) {
+			dirs[p] = true
+		}
+	}
+	tocs := dirs
+	if previous != nil {
+		tocs = make(map[string]bool)
+		affected := func(name string) {
+			for p := filepath.Dir(filepath.FromSlash(name)); p != "."; p = filepath.Dir(p) {
+				tocs[p] = true
+			}
+		}
+		for _, u := range leaves {
+			affected(path.Join(path.Dir(u.gofile), u.baseName))
+		}
+		for name := range previous {
+			if _, exists := virtual[name]; !exists {
+				affected(name)
+				if err := g.remove(name + ".go"); err != nil {
+					return *report, nil, err
+				}
+			}
+		}
+	}
+	for dir := range tocs {
+		if err := ctx.Err(); err != nil {
+			return *report, nil, err
+		}
+		if !dirs[dir] {
+			if err := g.remove(path.Join(filepath.ToSlash(dir), "generated-toc.go")); err != nil {
+				return *report, nil, err
+			}
+			continue
+		}
+		if err := newDirToc(g, importRoot, virtual, dir, filesByDirectory).Translate(); err != nil {
+			return *report, nil, err
+		}
+	}
+	if err := g.removeBlobs(virtual, leaves); err != nil {
+		return *report, nil, err
+	}
+	stage("toc")
+
+	// The fs interface implementation, unless shared
+	if opts.RuntimePackage == "" && opts.Runtime != nil && previous == nil {
+		if err := g.writeFile("generated-fs.go", opts.Runtime, false); err != nil {
+			return *report, nil, err
+		}
+	}
+	stage("runtime")
+	sort.Strings(report.GoFiles)
+	sort.Strings(report.Removed)
+	return *report, virtual, nil
+}
+
+// Writes the contents shared by several of the units translated once each,
+// in the blobs package, and returns the units to emit, referencing them.
+// Contents are the same if their stored data and codec are.
+func (g *generator) share(leaves []*translationUnit) ([]translator, error) {
+	groups := make(map[[sha256.Size]byte][]*translationUnit)
+	keys := [][sha256.Size]byte{}
+	emitters := []translator{}
+	for _, u := range leaves {
+		if u.unchanged {
+			continue
+		}
+		h := sha256.New()
+		io.WriteString(h, u.codec)
+		h.Write([]byte{0})
+		h.Write(u.data)
+		var key [sha256.Size]byte
+		copy(key[:], h.Sum(nil))
+		if groups[key] == nil {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], u)
+		emitters = append(emitters, emitter{u})
+	}
+	for _, key := range keys {
+		units := groups[key]
+		if len(units) < 2 {
+			continue
+		}
+		sort.Slice(units, func(i, j int) bool { return units[i].gofile < units[j].gofile })
+		name := hex.EncodeToString(key[:8])
+		for _, u := range units {
+			u.blob = "Blob_" + name
+		}
+		var buff bytes.Buffer
+		buff.Grow(len(units[0].data)*4 + 1024)
+		if err := writeBlob(&buff, units); err != nil {
+			return nil, err
+		}
+		if err := g.write(path.Join(blobsDir, name+".go"), buff.Bytes()); err != nil {
+			return nil, err
+		}
+		g.report.Saved += int64(len(units[0].data) * (len(units) - 1))
+		g.logf("Shared %d copies of %s --> %s", len(units), units[0].src, blobsDir)
+	}
+	return emitters, nil
+}
+
+// Removes the shared contents no file of the tree uses any more, if the
+// output can list them.  Files left unchanged may use any, so nothing is
+// removed then.
+func (g *generator) removeBlobs(virtual virtualTree, leaves []*translationUnit) error {
+	lister, ok := g.output.(Lister)
+	if !ok {
+		return nil
+	}
+	used := make(map[string]bool)
+	for name, src := range virtual {
+		if strings.HasPrefix(name, blobsDir+"/") {
+			return nil // not ours
+		}
+		used[src.blob] = true
+	}
+	for _, u := range leaves {
+		if u.unchanged {
+			return nil
+		}
+	}
+	names, err := lister.ReadDir(blobsDir)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		blob := strings.TrimSuffix(name, ".go")
+		if _, err := hex.DecodeString(blob); err != nil || len(blob) != 16 || used["Blob_"+blob] {
+			continue
+		}
+		if err := g.remove(path.Join(blobsDir, name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Removes a generated file, if the output can.
+func (g *generator) remove(name string) error {
+	remover, ok := g.output.(Remover)
+	if !ok {
+		return nil
+	}
+	if err := remover.Remove(name); err != nil {
+		return err
+	}
+	g.logf("Removed %s", name)
+	g.report.Removed = append(g.report.Removed, name)
+	return nil
+}
+
+// Checks the sizes of the files selected against the budgets, failing if
+// they are over any limit that isn't only a warning.
+func (g *generator) checkBudgets(ctx context.Context, virtual virtualTree) error {
+	if g.opts.Budgets == nil || len(g.opts.Budgets.Rules) == 0 {
+		return nil
+	}
+	files := make([]budgetFile, 0, len(virtual))
+	for name, src := range virtual {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		info, err := statSource(src.fsys, src.path)
+		if err != nil {
+			return err
+		}
+		files = append(files, budgetFile{path: name, size: info.Size()})
+	}
+	failures := 0
+	for _, v := range g.opts.Budgets.check(files) {
+		g.report.Budget = append(g.report.Budget, v)
+		if !v.Rule.Warn {
+			failures++
+		}
+	}
+	if failures > 0 {
+		return fmt.Errorf("%w: %d", ErrOverBudget, failures)
+	}
+	return nil
+}
+
+// Lists the sources and selects their files into the virtual tree.  Files
+// given twice are reported as conflicts.
+func (g *generator) collect(ctx context.Context) (virtualTree, error) {
+	virtual := virtualTree{}
+	for _, src := range g.opts.Sources {
+		fsys, files, err := listSource(src.Path)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read %s: %w", src.Path, err)
+		}
+		for _, file := range files {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+
+			selected, rule := g.selects(file)
+			if !selected {
+				g.logf("Skipping %s (%s)", file, rule)
+				g.report.Skipped = append(g.report.Skipped, SkippedFile{Source: file, Rule: rule})
+				continue
+			}
+			name, ok := src.virtualPath(file, fsys != nil, g.opts.Strip)
+			if !ok {
+				rule = fmt.Sprintf("strip %d", g.opts.Strip)
+				g.logf("Skipping %s (%s)", file, rule)
+				g.report.Skipped = append(g.report.Skipped, SkippedFile{Source: file, Rule: rule})
+				continue
+			}
+			name = path.Join(filepath.ToSlash(g.opts.Root), name)
+			if previous, exists := virtual[name]; exists {
+				g.report.Conflicts = append(g.report.Conflicts,
+					fmt.Sprintf("%s: from both %s and %s", name, previous, file))
+				continue
+			}
+			g.logf("Selected: %s --> %s\n", file, name)
+			origin := file
+			if fsys != nil {
+				origin = src.Path
+			}
+			virtual[name] = sourceFile{fsys: fsys, path: file, origin: origin, rule: rule}
+		}
+	}
+	return virtual, nil
+}
+
+// Returns whether the file is selected and the rule that decided it.
+func (g *generator) selects(file string) (bool, string) {
+	if g.opts.Match != nil && !g.opts.Match.MatchString(file) {
+		return false, "match " + g.opts.Match.String()
+	}
+	if g.opts.Exclude != nil && g.opts.Exclude.MatchString(file) {
+		return false, "exclude " + g.opts.Exclude.String()
+	}
+	if g.opts.Match != nil {
+		return true, "match " + g.opts.Match.String()
+	}
+	return true, "all"
+}
+
+// Returns the path in the virtual tree of a file listed from the source, or
+// false if stripping leaves nothing of it.
+func (s Source) virtualPath(file string, inArchive bool, strip int) (string, bool) {
+	if s.Virtual != "" {
+		rel := file
+		if !inArchive {
+			rel, _ = filepath.Rel(s.Path, file)
+		}
+		return path.Join(path.Clean("/" + s.Virtual)[1:], filepath.ToSlash(rel)), true
+	}
+	segments := strings.Split(path.Clean("/" + filepath.ToSlash(file))[1:], "/")
+	if len(segments) <= strip {
+		return "", false
+	}
+	return path.Join(segments[strip:]...), true
+}
+
+// Returns the files of a directory, with their paths on disk, or of an
+// archive, with their names in it and the tree read from it.
+func listSource(src string) (http.FileSystem, []string, error) {
+	stat, err := os.Stat(src)
+	switch {
+	case err != nil:
+		return nil, nil, err
+	case embedfs.IsArchive(src) && stat.Mode().IsRegular():
+		tree, err := embedfs.ReadSource(src)
+		if err != nil {
+			return nil, nil, err
+		}
+		fsys, err := tree.Sub(".")
+		if err != nil {
+			return nil, nil, err
+		}
+		files, err := listFiles(fsys, ".")
+		return fsys, files, err
+	case !stat.IsDir():
+		return nil, nil, fmt.Errorf("%s is not a directory or archive", src)
+	}
+	files := []string{}
+	err = filepath.Walk(src, func(p string, info os.FileInfo, err error) error {
+		if err == nil && info.Mode().IsRegular() {
+			files = append(files, filepath.Clean(p))
+		}
+		return err
+	})
+	return nil, files, err
+}
+
+// Returns the files below a directory of the file system, sorted.
+func listFiles(fsys http.FileSystem, dir string) ([]string, error) {
+	f, err := fsys.Open(dir)
+	if err != nil {
+		return nil, err
+	}
+	infos, err := f.Readdir(-1)
+	f.Close()
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name() < infos[j].Name() })
+	files := []string{}
+	for _, info := range infos {
+		name := path.Join(dir, info.Name())
+		if !info.IsDir() {
+			files = append(files, name)
+			continue
+		}
+		children, err := listFiles(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, children...)
+	}
+	return files, nil
+}
+
+// A file of the virtual tree, on disk if fsys is nil.
+type sourceFile struct {
+	fsys   http.FileSystem
+	path   string
+	origin string // the file on disk, or the archive holding it
+	rule   string // that selected it
+	blob   string // the constant in embedfs_blobs holding its contents, if shared
+}
+
+func (f sourceFile) String() string {
+	return f.path
+}
+
+// Files of the virtual tree, keyed by slash-separated path; opening one
+// opens its source.
+type virtualTree map[string]sourceFile
+
+func (v virtualTree) Open(name string) (http.File, error) {
+	f, exists := v[strings.TrimPrefix(filepath.ToSlash(name), "/")]
+	if !exists {
+		return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrNotExist}
+	}
+	return openSource(f.fsys, f.path)
+}
+
+// Returns the base names of the files, keyed by directory.
+func (v virtualTree) byDirectory() map[string][]string {
+	filesByDirectory := make(map[string][]string)
+	for name := range v {
+		dir := filepath.FromSlash(path.Dir(name))
+		filesByDirectory[dir] = append(filesByDirectory[dir], path.Base(name))
+	}
+	for _, files := range filesByDirectory {
+		sort.Strings(files)
+	}
+	return filesByDirectory
+}
+
+// Reports names that are both files and directories, files at the top of the
+// tree without a root, and directories or files whose generated names clash.
+func (v virtualTree) conflicts(filesByDirectory map[string][]string) []string {
+	conflicts := []string{}
+	dirs := make(map[string]bool)
+	for dir, files := range filesByDirectory {
+		if dir == "." {
+			conflicts = append(conflicts, fmt.Sprintf("%s: files at the top of the virtual tree need a root",
+				strings.Join(files, ", ")))
+		}
+		for p := dir; p != "."; p = filepath.Dir(p) {
+			dirs[p] = true
+		}
+		conflicts = append(conflicts, clashes(files, Sanitize2, func(file string) string {
+			return filepath.Join(dir, file)
+		}, "variable")...)
+	}
+	names := []string{}
+	for dir := range dirs {
+		names = append(names, dir)
+		if f, isFile := v[filepath.ToSlash(dir)]; isFile {
+			conflicts = append(conflicts, fmt.Sprintf("%s: both a directory and the file %s", dir, f))
+		}
+		if !importPathElementOK(filepath.Base(dir)) {
+			conflicts = append(conflicts, fmt.Sprintf("%q: not allowed in a Go import path, holding %s",
+				filepath.ToSlash(dir), v.firstBelow(filepath.ToSlash(dir))))
+		}
+	}
+	return append(conflicts, clashes(names, Sanitize, func(dir string) string { return dir }, "package")...)
+}
+
+// Whether a directory name can be part of the import path of its package,
+// by the rules of go/build: printable, without spaces or punctuation that
+// would need quoting.
+func importPathElementOK(name string) bool {
+	for _, r := range name {
+		if !unicode.IsGraphic(r) || unicode.IsSpace(r) || strings.ContainsRune("!\"#$%&'()*,:;<=>?[\\]^`{|}\uFFFD", r) {
+			return false
+		}
+	}
+	return true
+}
+
+// Returns the source of the first file below the directory.
+func (v virtualTree) firstBelow(dir string) sourceFile {
+	first := ""
+	for name := range v {
+		if strings.HasPrefix(name, dir+"/") && (first == "" || name < first) {
+			first = name
+		}
+	}
+	return v[first]
+}
+
+// Reports the names that come out the same once sanitized.
+func clashes(names []string, sanitize func(string) string, show func(string) string, kind string) []string {
+	bySanitized := make(map[string][]string)
+	for _, name := range names {
+		bySanitized[sanitize(name)] = append(bySanitized[sanitize(name)], show(name))
+	}
+	result := []string{}
+	for sanitized, same := range bySanitized {
+		if len(same) > 1 {
+			sort.Strings(same)
+			result = append(result, fmt.Sprintf("%s: same %s name %s", strings.Join(same, ", "), kind, sanitized))
+		}
+	}
+	return result
+}
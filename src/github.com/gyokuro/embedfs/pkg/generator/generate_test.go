@@ -0,0 +1,524 @@
+package generator
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+// Keeps generated files in memory.
+type memOutput struct {
+	lock  sync.Mutex
+	files fstest.MapFS
+}
+
+func newMemOutput() *memOutput {
+	return &memOutput{files: fstest.MapFS{}}
+}
+
+func (m *memOutput) WriteFile(name string, data []byte) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.files[name] = &fstest.MapFile{Data: data, Mode: 0644, ModTime: time.Now()}
+	return nil
+}
+
+func (m *memOutput) Stat(name string) (os.FileInfo, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return fs.Stat(m.files, name)
+}
+
+func (m *memOutput) Remove(name string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	delete(m.files, name)
+	return nil
+}
+
+func (m *memOutput) ReadDir(name string) ([]string, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	entries, err := fs.ReadDir(m.files, name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	names := []string{}
+	for _, e := range entries {
+		if !e.IsDir() {
+			names = append(names, e.Name())
+		}
+	}
+	return names, err
+}
+
+func (m *memOutput) contains(t *testing.T, name, text string) bool {
+	t.Helper()
+	f, exists := m.files[name]
+	if !exists {
+		t.Errorf("%s not generated", name)
+		return false
+	}
+	return bytes.Contains(f.Data, []byte(text))
+}
+
+var siteFiles = map[string]string{
+	"index.html":        "<html></html>",
+	"css/style.css":     strings.Repeat("body { margin: 0; }\n", 1000),
+	"js/lib/jquery.js":  "jQuery",
+	"js/lib/empty.js":   "",
+	"img/icons/a/b.png": "\x89PNG",
+}
+
+var siteTime = time.Date(2020, 5, 17, 12, 0, 0, 0, time.UTC)
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "embedfs-generate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// A site and a directory of shared files, on disk below root.
+func generateSources(t *testing.T) (root string) {
+	root = tempDir(t)
+	writeFiles(t, filepath.Join(root, "dist"), siteFiles)
+	writeFiles(t, filepath.Join(root, "shared"), map[string]string{
+		"img/logo.png": "\x89PNG logo",
+		"README":       "not selected",
+	})
+	return root
+}
+
+func TestGenerate(t *testing.T) {
+	root := generateSources(t)
+	defer os.RemoveAll(root)
+	out := newMemOutput()
+	report, err := Generate(context.Background(), Options{
+		ImportRoot: "example.com/out",
+		Sources: []Source{
+			{Path: filepath.Join(root, "dist"), Virtual: "/"},
+			{Path: filepath.Join(root, "shared", "img"), Virtual: "/img"},
+		},
+		Root:    "site",
+		Match:   regexp.MustCompile(`\.(html|css|js|png)$`),
+		Gofmt:   true,
+		Runtime: []byte("package embedfs\n"),
+		Output:  out,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	paths := []string{}
+	for _, f := range report.Files {
+		paths = append(paths, f.Path)
+	}
+	if got := strings.Join(paths, ","); got != "site/css/style.css,site/img/icons/a/b.png,site/img/logo.png,"+
+		"site/index.html,site/js/lib/empty.js,site/js/lib/jquery.js" {
+		t.Errorf("embedded %s", got)
+	}
+	if len(report.Skipped) != 0 {
+		t.Errorf("skipped %v", report.Skipped)
+	}
+	for _, name := range []string{"site/img/logo.png.go"} {
+		if !out.contains(t, name, "package site_img") {
+			t.Errorf("%s: package not named from the virtual path", name)
+		}
+	}
+	if !out.contains(t, "site/generated-toc.go", `{Path: "img/logo.png", File: &site_img.File_logo_png}`) {
+		t.Error("site/generated-toc.go does not index the mapped file")
+	}
+	if !out.contains(t, "generated-fs.go", "package embedfs") {
+		t.Error("runtime not written")
+	}
+	if !out.contains(t, "site/img/generated-toc.go", "func LogoPng() []byte") ||
+		!out.contains(t, "site/img/icons/a/generated-toc.go", "func OpenBPng() http.File") {
+		t.Error("accessors not generated")
+	}
+	if out.contains(t, "site/generated-toc.go", "LogoPng") {
+		t.Error("accessors of files below generated")
+	}
+	if !out.contains(t, "site/img/icons/a/b.png.go", "package site_img_icons_a") {
+		t.Error("b.png: package not named from the virtual path")
+	}
+	if len(report.GoFiles) != 6+7+1 {
+		t.Errorf("wrote %v", report.GoFiles)
+	}
+
+	// Dirs total the files directly in them.
+	if len(report.Dirs) != 5 || report.Dirs[0].Path != "site" || report.Dirs[0].Files != 1 ||
+		report.Dirs[2].Path != "site/img" || report.Dirs[2].Size != int64(len("\x89PNG logo")) {
+		t.Errorf("dirs %+v", report.Dirs)
+	}
+	stages := []string{}
+	for _, s := range report.Stages {
+		stages = append(stages, s.Name)
+	}
+	if got := strings.Join(stages, ","); got != "collect,check,translate,toc,runtime" {
+		t.Errorf("stages %s", got)
+	}
+}
+
+func TestGenerateConflicts(t *testing.T) {
+	root := generateSources(t)
+	defer os.RemoveAll(root)
+	writeFiles(t, filepath.Join(root, "shared"), map[string]string{
+		"index.html":  "another index",
+		"js":          "a file named as a directory",
+		"css-x/a.css": "",
+		"css_x/a.css": "",
+		"css_x/a-css": "",
+	})
+	out := newMemOutput()
+	report, err := Generate(context.Background(), Options{
+		ImportRoot: "example.com/out",
+		Sources: []Source{
+			{Path: filepath.Join(root, "dist"), Virtual: "/"},
+			{Path: filepath.Join(root, "shared"), Virtual: "/"},
+		},
+		Output: out,
+	})
+	if !errors.Is(err, ErrConflict) {
+		t.Fatalf("got %v, want ErrConflict", err)
+	}
+	want := []string{
+		"README, index.html, js: files at the top of the virtual tree need a root",
+		"css-x, css_x: same package name css_x",
+		"css_x/a-css, css_x/a.css: same variable name a_css",
+		"index.html: from both " + filepath.Join(root, "dist", "index.html") + " and " + filepath.Join(root, "shared", "index.html"),
+		"js: both a directory and the file " + filepath.Join(root, "shared", "js"),
+	}
+	if got := strings.Join(report.Conflicts, "\n"); got != strings.Join(want, "\n") {
+		t.Errorf("conflicts:\n%s\nwant:\n%s", got, strings.Join(want, "\n"))
+	}
+	if len(out.files) != 0 {
+		t.Errorf("wrote %d files despite conflicts", len(out.files))
+	}
+}
+
+func TestGenerateFromArchive(t *testing.T) {
+	root := tempDir(t)
+	defer os.RemoveAll(root)
+	archive := filepath.Join(root, "site.zip")
+	f, err := os.Create(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := zip.NewWriter(f)
+	for name, content := range siteFiles {
+		entry, err := w.CreateHeader(&zip.FileHeader{Name: "top/" + name, Method: zip.Deflate, Modified: siteTime})
+		if err != nil {
+			t.Fatal(err)
+		}
+		entry.Write([]byte(content))
+	}
+	w.Close()
+	f.Close()
+
+	out := newMemOutput()
+	report, err := Generate(context.Background(), Options{
+		ImportRoot: "example.com/out",
+		Sources:    []Source{{Path: archive}},
+		Strip:      1,
+		Root:       "site",
+		Output:     out,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Files) != len(siteFiles) || report.Files[0].Path != "site/css/style.css" ||
+		report.Files[0].Source != "top/css/style.css" {
+		t.Errorf("embedded %v", report.Files)
+	}
+	// Times of the archive entries are kept.
+	if !out.contains(t, "site/index.html.go", strconv.FormatInt(siteTime.UnixNano(), 10)) {
+		t.Error("time of the archive entry not kept")
+	}
+}
+
+// Names of archive entries may hold anything; the generated source must
+// still say exactly them.
+func TestGenerateQuotesNames(t *testing.T) {
+	root := tempDir(t)
+	defer os.RemoveAll(root)
+	archive := filepath.Join(root, "odd.zip")
+	f, err := os.Create(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := zip.NewWriter(f)
+	names := []string{`dir/"a".txt`, "dir/new\nline.txt", "dir/tab\t.txt"}
+	for _, name := range names {
+		entry, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		entry.Write([]byte(name))
+	}
+	w.Close()
+	f.Close()
+
+	out := newMemOutput()
+	report, err := Generate(context.Background(), Options{
+		ImportRoot: "example.com/out",
+		Sources:    []Source{{Path: archive, Virtual: "site"}},
+		Gofmt:      true, // fails on source that doesn't parse
+		Output:     out,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Files) != len(names) {
+		t.Fatalf("embedded %v", report.Files)
+	}
+	for _, f := range report.Files {
+		if !out.contains(t, f.GoFile, strconv.Quote(path.Base(f.Path))+",") {
+			t.Errorf("%s: name not quoted", f.GoFile)
+		}
+	}
+	if !out.contains(t, "site/dir/new\nline.txt.go", "// AUTO-GENERATED FROM "+strconv.Quote("dir/new\nline.txt")+"\n") ||
+		!out.contains(t, "site/dir/generated-toc.go", "// Opens "+strconv.Quote("new\nline.txt")+".\n") {
+		t.Error("newline not escaped in comments")
+	}
+}
+
+// Virtual paths chosen with Virtual and Root end up in import paths, which
+// can't hold everything file names can.
+func TestGenerateRejectsImportPaths(t *testing.T) {
+	root := tempDir(t)
+	defer os.RemoveAll(root)
+	writeFiles(t, root, map[string]string{`a "b".txt`: "quoted", "c.txt": "c"})
+	out := newMemOutput()
+	report, err := Generate(context.Background(), Options{
+		ImportRoot: "example.com/out",
+		Sources:    []Source{{Path: root, Virtual: `site/we"ird`}},
+		Root:       "my site",
+		Output:     out,
+	})
+	if !errors.Is(err, ErrConflict) {
+		t.Fatalf("got %v, want ErrConflict", err)
+	}
+	source := filepath.Join(root, `a "b".txt`)
+	want := []string{
+		`"my site": not allowed in a Go import path, holding ` + source,
+		`"my site/site/we\"ird": not allowed in a Go import path, holding ` + source,
+	}
+	if strings.Join(report.Conflicts, "\n") != strings.Join(want, "\n") {
+		t.Errorf("conflicts:\n%s\nwant:\n%s", strings.Join(report.Conflicts, "\n"), strings.Join(want, "\n"))
+	}
+
+	// Names of files only go in literals, quoted.
+	report, err = Generate(context.Background(), Options{
+		ImportRoot: "example.com/out",
+		Sources:    []Source{{Path: root, Virtual: "/"}},
+		Root:       "site",
+		Gofmt:      true,
+		Output:     out,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !out.contains(t, "site/a \"b\".txt.go", `"a \"b\".txt",`) {
+		t.Error("mapped name not quoted")
+	}
+}
+
+func TestGenerateKeepNewer(t *testing.T) {
+	root := generateSources(t)
+	defer os.RemoveAll(root)
+	opts := Options{
+		ImportRoot: "example.com/out",
+		Sources:    []Source{{Path: filepath.Join(root, "dist"), Virtual: "site"}},
+		Output:     newMemOutput(),
+	}
+	if _, err := Generate(context.Background(), opts); err != nil {
+		t.Fatal(err)
+	}
+	opts.KeepNewer = true
+	report, err := Generate(context.Background(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range report.Files {
+		if !f.Unchanged {
+			t.Errorf("%s generated again", f.Path)
+		}
+	}
+
+	opts.DryRun, opts.KeepNewer, opts.Output = true, false, newMemOutput()
+	if report, err = Generate(context.Background(), opts); err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Files) != len(siteFiles) || len(opts.Output.(*memOutput).files) != 0 {
+		t.Errorf("dry run: reported %d files, wrote %d", len(report.Files), len(opts.Output.(*memOutput).files))
+	}
+}
+
+func TestGenerateDedup(t *testing.T) {
+	root := tempDir(t)
+	defer os.RemoveAll(root)
+	jquery := strings.Repeat("jQuery", 100)
+	writeFiles(t, root, map[string]string{
+		"a/js/jquery.js": jquery,
+		"b/js/jquery.js": jquery,
+		"b/lib.js":       jquery,
+		"b/index.html":   "<html></html>",
+	})
+	os.Chtimes(filepath.Join(root, "b", "lib.js"), siteTime, siteTime)
+	out := newMemOutput()
+	report, err := Generate(context.Background(), Options{
+		ImportRoot: "example.com/out",
+		Sources:    []Source{{Path: root, Virtual: "site"}},
+		Dedup:      true,
+		Gofmt:      true,
+		Output:     out,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var blob string
+	for _, f := range report.Files {
+		if f.Path == "site/b/index.html" {
+			if f.Blob != "" {
+				t.Errorf("%s shares %s", f.Path, f.Blob)
+			}
+			continue
+		}
+		if blob == "" {
+			blob = f.Blob
+		}
+		if f.Blob == "" || f.Blob != blob {
+			t.Errorf("%s shares %q, want %q", f.Path, f.Blob, blob)
+		}
+		if !out.contains(t, f.GoFile, "blobs."+blob+",") {
+			t.Errorf("%s does not use the shared contents", f.GoFile)
+		}
+	}
+	blobs := 0
+	for name := range out.files {
+		if strings.HasPrefix(name, "embedfs_blobs/") {
+			blobs++
+		}
+	}
+	if blobs != 1 || report.Saved != 2*int64(len(jquery)) {
+		t.Errorf("%d blobs saving %d bytes", blobs, report.Saved)
+	}
+	// Names and times stay those of each file.
+	if !out.contains(t, "site/b/lib.js.go", `"lib.js",`) ||
+		!out.contains(t, "site/b/lib.js.go", strconv.FormatInt(siteTime.UnixNano(), 10)) {
+		t.Error("lib.js: name or time not its own")
+	}
+	if !out.contains(t, "site/b/index.html.go", "<html></html>") {
+		t.Error("index.html: contents not in its own file")
+	}
+
+	// Once the contents are no longer shared, their blob goes.
+	os.Remove(filepath.Join(root, "b", "js", "jquery.js"))
+	os.Remove(filepath.Join(root, "b", "lib.js"))
+	report, err = Generate(context.Background(), Options{
+		ImportRoot: "example.com/out",
+		Sources:    []Source{{Path: root, Virtual: "site"}},
+		Dedup:      true,
+		Output:     out,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Removed) != 1 || report.Removed[0] != "embedfs_blobs/"+strings.TrimPrefix(blob, "Blob_")+".go" ||
+		report.Saved != 0 {
+		t.Errorf("removed %v, saved %d", report.Removed, report.Saved)
+	}
+
+	_, err = Generate(context.Background(), Options{
+		ImportRoot: "example.com/out",
+		Sources:    []Source{{Path: root, Virtual: "/"}, {Path: filepath.Join(root, "a"), Virtual: blobsDir}},
+		Dedup:      true,
+		Output:     newMemOutput(),
+	})
+	if !errors.Is(err, ErrConflict) {
+		t.Errorf("got %v, want ErrConflict", err)
+	}
+}
+
+// Files not generated again keep their blob; it goes with the last of them.
+func TestGenerateDedupIncremental(t *testing.T) {
+	root := tempDir(t)
+	defer os.RemoveAll(root)
+	writeFiles(t, root, map[string]string{"a.js": "jQuery", "b.js": "jQuery", "c.js": "jQuery"})
+	out := newMemOutput()
+	opts := Options{
+		ImportRoot: "example.com/out",
+		Sources:    []Source{{Path: root, Virtual: "site"}},
+		Dedup:      true,
+		Output:     out,
+	}
+	ctx := context.Background()
+	report, tree, err := generate(ctx, opts, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blob := "embedfs_blobs/" + strings.TrimPrefix(report.Files[0].Blob, "Blob_") + ".go"
+	for _, step := range []struct {
+		change  func()
+		changed string
+		removed string
+	}{
+		{func() { writeFiles(t, root, map[string]string{"c.js": "Zepto"}) }, "c.js", ""},
+		{func() { os.Remove(filepath.Join(root, "b.js")) }, "b.js", "site/b.js.go"},
+		{func() { os.Remove(filepath.Join(root, "a.js")) }, "a.js", blob + ",site/a.js.go"},
+	} {
+		step.change()
+		report, tree, err = generate(ctx, opts, tree, map[string]bool{filepath.Join(root, step.changed): true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := strings.Join(report.Removed, ","); got != step.removed {
+			t.Errorf("%s changed: removed %s, want %s", step.changed, got, step.removed)
+		}
+	}
+	if _, exists := out.files[blob]; exists {
+		t.Errorf("%s not removed", blob)
+	}
+}
+
+func TestGenerateCancelled(t *testing.T) {
+	root := generateSources(t)
+	defer os.RemoveAll(root)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := Generate(ctx, Options{
+		ImportRoot: "example.com/out",
+		Sources:    []Source{{Path: root, Virtual: "site"}},
+		Output:     newMemOutput(),
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,511 @@
+package generator
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"hash/fnv"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Turns a file name into part of an identifier: every rune that can't be
+// in one, dots and dashes most often, becomes an underscore.
+func Sanitize2(n string) (value string) {
+	return strings.Map(func(r rune) rune {
+		if r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return '_'
+	}, n)
+}
+
+// Turns a directory of the virtual tree into a package name, separators
+// included.
+func Sanitize(n string) (value string) {
+	return Sanitize2(n)
+}
+
+// Name of the exported variable holding an embedded file, so that the TOCs
+// of parent directories can reference it.
+func fileVarName(basename string) string {
+	return "File_" + Sanitize2(basename)
+}
+
+// Identifiers of a TOC's package other than the accessors of its files.
+var tocIdentifiers = []string{"DIR", "Dir", "Mount", "FileInfo", "ReadFile", "Bytes"}
+
+// Names the accessors of the files in a TOC's directory, by path relative
+// to it: N for the function returning the contents, OpenN and the constant
+// PathN.  N is the path in CamelCase, "style.min.css" giving StyleMinCss.
+// Paths whose identifiers clash with each other's, or with those reserved,
+// get a hash of the path appended instead, so that a name never moves from
+// one file to another as files come and go.  The file already there is
+// renamed all the same when a clashing one appears.
+func accessorNames(paths []string, reserved map[string]bool) map[string]string {
+	identifiers := func(name string) []string {
+		return []string{name, "Open" + name, "Path" + name}
+	}
+	users := make(map[string]int)
+	for _, p := range paths {
+		for _, id := range identifiers(camelCase(p)) {
+			users[id]++
+		}
+	}
+	names := make(map[string]string)
+	for _, p := range paths {
+		name := camelCase(p)
+		for _, id := range identifiers(name) {
+			if users[id] > 1 || reserved[id] {
+				h := fnv.New32a()
+				io.WriteString(h, p)
+				name = fmt.Sprintf("%s_%08x", name, h.Sum32())
+				break
+			}
+		}
+		names[p] = name
+	}
+	return names
+}
+
+// Turns a path into an exported identifier: letters and digits, each run
+// of them starting with a capital, and "File" in front if there is no
+// capital to start with.  The result has no underscores.
+func camelCase(p string) string {
+	var b strings.Builder
+	start := true
+	for _, r := range p {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			start = true
+			continue
+		}
+		if start {
+			r, start = unicode.ToUpper(r), false
+		}
+		b.WriteRune(r)
+	}
+	name := b.String()
+	if first, _ := utf8.DecodeRuneInString(name); !unicode.IsUpper(first) {
+		name = "File" + name
+	}
+	return name
+}
+
+// Creates the unit embedding srcFile, read from source, or from disk if
+// source is nil, into the Go file for basename in outDir.
+func newTranslationUnit(g *generator, packageName string, source http.FileSystem, srcFile string,
+	basename string, outDir string) *translationUnit {
+	return &translationUnit{
+		g:           g,
+		source:      source,
+		name:        fileVarName(basename),
+		dataName:    "data_" + Sanitize2(basename),
+		baseName:    basename,
+		src:         srcFile,
+		gofile:      path.Join(outDir, basename+".go"),
+		packageName: packageName,
+	}
+}
+
+// Creates the TOC for a directory.  files holds every selected file, keyed
+// by directory; the TOC indexes those below dirName.  Their times are read
+// from source.
+func newDirToc(g *generator, importRoot string, source http.FileSystem, dirName string,
+	files map[string][]string) *dirToc {
+	return &dirToc{
+		g:          g,
+		source:     source,
+		importRoot: importRoot,
+		dirName:    dirName,
+		files:      files,
+		gofile:     path.Join(filepath.ToSlash(dirName), "generated-toc.go"),
+	}
+}
+
+type dirToc struct {
+	g          *generator
+	source     http.FileSystem
+	importRoot string
+	dirName    string              // directory of the virtual tree
+	files      map[string][]string // base names of all selected files, keyed by directory
+	gofile     string              // relative to the output
+}
+
+func (d *dirToc) String() string {
+	return fmt.Sprintf("%s --> %s", d.dirName, d.gofile)
+}
+
+// An entry of the generated index: the path relative to the TOC's directory,
+// the Go expression for the file or directory, and the name of the file's
+// accessors if it is directly in the directory.
+type tocEntry struct {
+	Path string
+	File string
+	Dir  string
+	Name string
+}
+
+// Builds the sorted index of everything below the directory, the imports of
+// the descendant packages it references, and the directory's modification
+// time, which is that of the newest file below it.
+//
+// Every ancestor's index repeats the entries of all the files below it, so
+// the indexes of a tree grow with the number of files times their depth.
+// In exchange EmbedDir finds any path below it with one map lookup and no
+// walking of subdirectories, and its index is static data; a tree of a few
+// thousand files a few levels deep costs some hundreds of kilobytes.
+func (d *dirToc) buildIndex() (entries []tocEntry, imports map[string]string, modTime int64, err error) {
+	imports = make(map[string]string)
+	dirs := make(map[string]bool)
+	prefix := d.dirName + string(filepath.Separator)
+
+	for dir, files := range d.files {
+		if dir != d.dirName && !strings.HasPrefix(dir, prefix) {
+			continue
+		}
+		alias := ""
+		if dir != d.dirName {
+			alias = Sanitize(dir) + "."
+			imports[Sanitize(dir)] = filepath.ToSlash(filepath.Join(d.importRoot, dir))
+			// Directories between this one and dir may hold no files.
+			for p := dir; p != d.dirName; p = filepath.Dir(p) {
+				dirs[p] = true
+			}
+		}
+		for _, file := range files {
+			stat, err := statSource(d.source, filepath.Join(dir, file))
+			if err != nil {
+				return nil, nil, 0, err
+			}
+			if t := stat.ModTime().UnixNano(); t > modTime {
+				modTime = t
+			}
+			rel, _ := filepath.Rel(d.dirName, filepath.Join(dir, file))
+			entries = append(entries, tocEntry{
+				Path: filepath.ToSlash(rel),
+				File: alias + fileVarName(file),
+			})
+		}
+	}
+	for dir := range dirs {
+		imports[Sanitize(dir)] = filepath.ToSlash(filepath.Join(d.importRoot, dir))
+		rel, _ := filepath.Rel(d.dirName, dir)
+		entries = append(entries, tocEntry{
+			Path: filepath.ToSlash(rel),
+			Dir:  Sanitize(dir) + ".DIR",
+		})
+	}
+	sort.Sort(byPath(entries))
+
+	// Accessors for the files directly in the directory, clear of the other
+	// identifiers; those below are the subpackages'.
+	reserved := make(map[string]bool)
+	for _, id := range tocIdentifiers {
+		reserved[id] = true
+	}
+	for alias := range imports {
+		reserved[alias] = true
+	}
+	for _, file := range d.files[d.dirName] {
+		reserved[fileVarName(file)] = true
+	}
+	paths := []string{}
+	for _, file := range d.files[d.dirName] {
+		paths = append(paths, file)
+	}
+	names := accessorNames(paths, reserved)
+	for i, e := range entries {
+		if name, own := names[e.Path]; own && e.File != "" {
+			entries[i].Name = name
+		}
+	}
+	return
+}
+
+type byPath []tocEntry
+
+func (s byPath) Len() int           { return len(s) }
+func (s byPath) Less(i, j int) bool { return s[i].Path < s[j].Path }
+func (s byPath) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+func (d *dirToc) Translate() error {
+	var buff bytes.Buffer
+	if err := d.writeDirToc(&buff); err != nil {
+		d.g.logf("FAIL to generate toc --> %s\n", d.gofile)
+		return err
+	}
+	if err := d.g.write(d.gofile, buff.Bytes()); err != nil {
+		return err
+	}
+	d.g.logf("Generated toc --> %s\n", d.gofile)
+	return nil
+}
+
+// Formats generated source as gofmt does.
+func gofmt(name string, src []byte) ([]byte, error) {
+	fileSet := token.NewFileSet()
+	ast, err := parser.ParseFile(fileSet, name, src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	var formatted bytes.Buffer
+	config := &printer.Config{
+		Mode:     printer.TabIndent | printer.UseSpaces,
+		Tabwidth: 8,
+	}
+	if err := config.Fprint(&formatted, fileSet, ast); err != nil {
+		return nil, err
+	}
+	return formatted.Bytes(), nil
+}
+
+type translationUnit struct {
+	g           *generator
+	source      http.FileSystem
+	name        string
+	dataName    string
+	baseName    string
+	src         string
+	gofile      string // relative to the output
+	packageName string
+	codec       string
+	data        []byte
+	blob        string // the shared constant holding data, if any
+	fileInfo    os.FileInfo
+	unchanged   bool
+	writer      io.Writer
+}
+
+// Reads from disk, or from the file system given.
+func openSource(source http.FileSystem, name string) (http.File, error) {
+	if source == nil {
+		return os.Open(name)
+	}
+	return source.Open(filepath.ToSlash(name))
+}
+
+func statSource(source http.FileSystem, name string) (os.FileInfo, error) {
+	if source == nil {
+		return os.Stat(name)
+	}
+	f, err := openSource(source, name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return f.Stat()
+}
+
+func readSource(source http.FileSystem, name string) ([]byte, error) {
+	f, err := openSource(source, name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ioutil.ReadAll(f)
+}
+
+func (u *translationUnit) String() string {
+	return fmt.Sprintf("%s --> %s (package %s)", u.src, u.gofile, u.packageName)
+}
+
+// Encodings of every byte value inside a Go string literal: printable ASCII
+// stands for itself, everything else is a \xNN escape.
+var stringTable [256][]byte
+
+func init() {
+	const hex = "0123456789abcdef"
+	for b := 0; b < 256; b++ {
+		if b >= 0x20 && b < 0x7f && b != '"' && b != '\\' {
+			stringTable[b] = []byte{byte(b)}
+		} else {
+			stringTable[b] = []byte{'\\', 'x', hex[b>>4], hex[b&0xf]}
+		}
+	}
+}
+
+func (u *translationUnit) Write(p []byte) (n int, err error) {
+	if len(p) == 0 {
+		return
+	}
+	// Encode into a local chunk so the writer sees a few large writes
+	// instead of one per byte.
+	var chunk [4096]byte
+	out := chunk[:0]
+	for _, b := range p {
+		out = append(out, stringTable[b]...)
+		if len(out) > len(chunk)-4 {
+			if _, err = u.writer.Write(out); err != nil {
+				return
+			}
+			out = out[:0]
+		}
+	}
+	if _, err = u.writer.Write(out); err != nil {
+		return
+	}
+	return len(p), nil
+}
+
+// Writes the data as a string literal, for a constant that the linker keeps
+// in read-only memory.
+func (u *translationUnit) writeBinaryRepresentation() error {
+	io.WriteString(u.writer, "\"")
+	if _, err := u.Write(u.data); err != nil {
+		return err
+	}
+	_, err := io.WriteString(u.writer, "\"")
+	return err
+}
+
+func (u *translationUnit) Translate() error {
+	u.g.logf("Translating %s", u.src)
+	source, err := statSource(u.source, u.src)
+	if err != nil {
+		return err
+	}
+	u.fileInfo = source
+
+	if u.g.opts.KeepNewer {
+		goStat, err := u.g.output.Stat(u.gofile)
+		if err == nil && goStat.ModTime().After(source.ModTime()) {
+			// file exits and is *after* the mod time of source -- do nothing
+			u.g.logf("Skipping %s", u.gofile)
+			u.unchanged = true
+			return nil
+		}
+	}
+
+	// Read the source once; every codec tried works from this copy.
+	original, err := readSource(u.source, u.src)
+	if err != nil {
+		return err
+	}
+
+	if u.codec, u.data, err = u.g.opts.Compression.Compress(u.src, original); err != nil {
+		return err
+	}
+	if u.g.opts.Dedup {
+		// Written by emit once the data shared is known
+		return nil
+	}
+	return u.emit()
+}
+
+// Writes the Go file of a translated unit.
+func (u *translationUnit) emit() error {
+	var buff bytes.Buffer
+	if u.blob == "" {
+		buff.Grow(len(u.data)*4 + 1024)
+	}
+	if err := u.writeLeafNode(&buff); err != nil {
+		u.g.logf("FAIL to generate %s --> %s\n", u.src, u.gofile)
+		return err
+	}
+	if err := u.g.write(u.gofile, buff.Bytes()); err != nil {
+		return err
+	}
+	u.g.logf("Generated %s --> %s\n", u.src, u.gofile)
+	return nil
+}
+
+// Translates by writing the Go file of a unit already translated.
+type emitter struct {
+	*translationUnit
+}
+
+func (e emitter) Translate() error {
+	return e.emit()
+}
+
+// Generates one Go source file; implemented by translation units and
+// directory TOCs.
+type translator interface {
+	Translate() error
+}
+
+// Translates the units with up to workers goroutines.  Stops handing out
+// work after the first failure, or once the context is done, and returns
+// that error.
+func translateAll(ctx context.Context, units []translator, workers int) error {
+	if workers < 1 {
+		workers = 1
+	}
+	work := make(chan translator)
+	failed := make(chan error, len(units)+1)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for u := range work {
+				if err := u.Translate(); err != nil {
+					failed <- err
+				}
+			}
+		}()
+	}
+
+dispatch:
+	for _, u := range units {
+		select {
+		case err := <-failed:
+			failed <- err
+			break dispatch
+		case <-ctx.Done():
+			failed <- ctx.Err()
+			break dispatch
+		case work <- u:
+		}
+	}
+	close(work)
+	wg.Wait()
+
+	select {
+	case err := <-failed:
+		return err
+	default:
+		return nil
+	}
+}
+
+var matcher, _ = regexp.Compile("^(src|pkg)/")
+
+// Searches the GOPATH and checks if the given path (absolute path)
+// is relative to a component in the GOPATH.  If so, return the path
+// without the src or pkg so that the path is suitable for using as
+// import statements
+func CheckGoPath(path string) (string, error) {
+	pathenv := os.Getenv("GOPATH")
+	if pathenv == "" {
+		return "", errors.New("not found")
+	}
+	for _, dir := range strings.Split(pathenv, ":") {
+		if dir == "" {
+			// Unix shell semantics: path element "" means "."
+			dir = "."
+		}
+		if rel, err := filepath.Rel(dir, path); err == nil {
+			if matcher.MatchString(rel) {
+				return matcher.ReplaceAllString(rel, ""), err
+			} else {
+				return rel, err
+			}
+		}
+	}
+	return "", errors.New("not found")
+}
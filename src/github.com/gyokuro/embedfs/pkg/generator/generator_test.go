@@ -0,0 +1,136 @@
+package generator
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func randomData(size int) []byte {
+	data := make([]byte, size)
+	rand.New(rand.NewSource(int64(size))).Read(data)
+	return data
+}
+
+// The encoder as it was before the lookup tables: one Fprintf per byte.
+func fprintfEncode(w io.Writer, data []byte) {
+	for _, b := range data {
+		fmt.Fprintf(w, "\\x%02x", b)
+	}
+}
+
+func TestWriteRoundTrips(t *testing.T) {
+	for _, size := range []int{0, 1, 255, 4095, 100000} {
+		data := randomData(size)
+		data = append(data, "plain \"quoted\" \\ text\n"...)
+		var got bytes.Buffer
+
+		u := &translationUnit{data: data, writer: &got}
+		if err := u.writeBinaryRepresentation(); err != nil {
+			t.Fatal(err)
+		}
+		unquoted, err := strconv.Unquote(got.String())
+		if err != nil {
+			t.Fatalf("size %d: %s", size, err)
+		}
+		if unquoted != string(data) {
+			t.Errorf("size %d: data does not round trip", size)
+		}
+	}
+}
+
+func TestAccessorNames(t *testing.T) {
+	reserved := map[string]bool{"Mount": true, "File_logo_png": true}
+	names := accessorNames([]string{
+		"css.style.css", "demo.html", "404.html", "mount",
+		"a-b.css", "a_b.css", "open.demo.css", "demo.css", "logo.png",
+	}, reserved)
+	for p, want := range map[string]string{
+		"css.style.css": "CssStyleCss",
+		"demo.html":     "DemoHtml",
+		"404.html":      "File404Html",
+		"logo.png":      "LogoPng",
+	} {
+		if names[p] != want {
+			t.Errorf("%s: got %s, want %s", p, names[p], want)
+		}
+	}
+	// Clashing names are all hashed, each differently.
+	seen := map[string]bool{}
+	for _, p := range []string{"mount", "a-b.css", "a_b.css", "open.demo.css", "demo.css"} {
+		name := names[p]
+		if !strings.Contains(name, "_") || seen[name] {
+			t.Errorf("%s: got %s", p, name)
+		}
+		seen[name] = true
+	}
+	if again := accessorNames([]string{"a-b.css", "a_b.css"}, nil); again["a-b.css"] != names["a-b.css"] {
+		t.Errorf("a-b.css named %s, then %s", names["a-b.css"], again["a-b.css"])
+	}
+}
+
+func benchmarkEncode(b *testing.B, encode func(io.Writer, []byte)) {
+	data := randomData(1 << 20)
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		encode(ioutil.Discard, data)
+	}
+}
+
+func BenchmarkEncodeFprintf(b *testing.B) {
+	benchmarkEncode(b, fprintfEncode)
+}
+
+func BenchmarkEncodeTable(b *testing.B) {
+	benchmarkEncode(b, func(w io.Writer, data []byte) {
+		u := &translationUnit{data: data, writer: w}
+		u.Write(data)
+	})
+}
+
+func benchmarkTranslate(b *testing.B, workers int) {
+	src, err := ioutil.TempDir("", "embedfs-src")
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer os.RemoveAll(src)
+
+	const files, size = 32, 256 << 10
+	for i := 0; i < files; i++ {
+		name := fmt.Sprintf("file%d.bin", i)
+		if err := ioutil.WriteFile(filepath.Join(src, name), randomData(size+i), 0644); err != nil {
+			b.Fatal(err)
+		}
+	}
+	opts := Options{
+		ImportRoot: "example.com/out",
+		Sources:    []Source{{Path: src, Virtual: "out"}},
+		Workers:    workers,
+	}
+	b.SetBytes(files * size)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		opts.Output = newMemOutput()
+		if _, err := Generate(context.Background(), opts); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkTranslateSerial(b *testing.B) {
+	benchmarkTranslate(b, 1)
+}
+
+func BenchmarkTranslateParallel(b *testing.B) {
+	benchmarkTranslate(b, runtime.NumCPU())
+}
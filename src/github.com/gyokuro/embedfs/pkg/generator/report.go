@@ -0,0 +1,80 @@
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+// Writes the report as indented JSON.  Everything but the times of the
+// stages is the same from one run to the next on the same sources, so
+// reports of releases can be diffed.
+func (r Report) WriteJSON(w io.Writer) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// Writes the report as aligned tables: the files embedded, those skipped,
+// the directories, conflicts and budget violations, and the stages.
+func (r Report) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "FILE\tSOURCE\tRULE\tSIZE\tSTORED\tCODEC\tRATIO\tGO FILE\tPACKAGE\tCOMPRESSION")
+	for _, f := range r.Files {
+		stored, codec, ratio := fmt.Sprint(f.StoredSize), f.Codec, fmt.Sprintf("%.3f", f.Ratio)
+		if f.Unchanged {
+			stored, codec, ratio = "-", "unchanged", "-"
+		} else if codec == "" {
+			codec = "none"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			f.Path, f.Source, f.Rule, f.Size, stored, codec, ratio, f.GoFile, f.Package, f.Compression)
+	}
+	if len(r.Skipped) > 0 {
+		fmt.Fprintln(tw, "\nSKIPPED\tRULE")
+		for _, f := range r.Skipped {
+			fmt.Fprintf(tw, "%s\t%s\n", f.Source, f.Rule)
+		}
+	}
+	fmt.Fprintln(tw, "\nDIR\tFILES\tSIZE\tSTORED")
+	var files int
+	var size, stored int64
+	for _, d := range r.Dirs {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\n", d.Path, d.Files, d.Size, d.StoredSize)
+		files, size, stored = files+d.Files, size+d.Size, stored+d.StoredSize
+	}
+	fmt.Fprintf(tw, "total\t%d\t%d\t%d\n", files, size, stored)
+	if r.Saved > 0 {
+		fmt.Fprintf(tw, "shared\t\t\t-%d\n", r.Saved)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if len(r.Conflicts)+len(r.Budget) > 0 {
+		fmt.Fprintln(w)
+	}
+	for _, c := range r.Conflicts {
+		fmt.Fprintln(w, "conflict:", c)
+	}
+	for _, v := range r.Budget {
+		kind := "over budget:"
+		if v.Rule.Warn {
+			kind = "warning:"
+		}
+		fmt.Fprintln(w, kind, v)
+		for _, o := range v.Offenders {
+			fmt.Fprintf(w, "    %d %s\n", o.Size, o.Path)
+		}
+	}
+
+	fmt.Fprintln(tw, "\nSTAGE\tELAPSED")
+	for _, s := range r.Stages {
+		fmt.Fprintf(tw, "%s\t%s\n", s.Name, s.Elapsed)
+	}
+	return tw.Flush()
+}
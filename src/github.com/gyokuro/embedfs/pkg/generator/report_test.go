@@ -0,0 +1,70 @@
+package generator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+)
+
+func TestReport(t *testing.T) {
+	root := generateSources(t)
+	defer os.RemoveAll(root)
+	report, err := Generate(context.Background(), Options{
+		ImportRoot: "example.com/out",
+		Sources:    []Source{{Path: filepath.Join(root, "dist"), Virtual: "site"}},
+		Match:      regexp.MustCompile(`\.(html|css|js)$`),
+		Exclude:    regexp.MustCompile(`empty`),
+		Output:     newMemOutput(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rules := map[string]string{}
+	for _, f := range report.Skipped {
+		rules[filepath.ToSlash(f.Source)] = f.Rule
+	}
+	for _, f := range report.Files {
+		rules[filepath.ToSlash(f.Source)] = f.Rule
+		if f.Path == "site/css/style.css" && (f.Codec != "zlib" || f.Ratio <= 0 || f.Ratio >= 0.5 ||
+			f.Compression != "*=auto:zlib:-1") {
+			t.Errorf("style.css stored %+v", f)
+		}
+	}
+	dist := filepath.ToSlash(filepath.Join(root, "dist")) + "/"
+	for file, want := range map[string]string{
+		"index.html":        `match \.(html|css|js)$`,
+		"js/lib/empty.js":   "exclude empty",
+		"img/icons/a/b.png": `match \.(html|css|js)$`,
+	} {
+		if rules[dist+file] != want {
+			t.Errorf("%s: rule %q, want %q", file, rules[dist+file], want)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := report.WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var decoded Report
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded.Files) != 3 || decoded.Files[0] != report.Files[0] || len(decoded.Skipped) != 2 {
+		t.Errorf("JSON decodes to %+v", decoded)
+	}
+
+	buf.Reset()
+	if err := report.WriteText(&buf); err != nil {
+		t.Fatal(err)
+	}
+	for _, text := range []string{`site/css/style\.css .* zlib +0\.005 `, "exclude empty", `total +3 +20019 +\d+`, "translate"} {
+		if !regexp.MustCompile(text).MatchString(buf.String()) {
+			t.Errorf("text report lacks %s:\n%s", text, buf.String())
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package generator
+
+import (
+	"io"
+	"path/filepath"
+	"text/template"
+
+	"github.com/gyokuro/embedfs/pkg/embedfs"
+)
+
+const dirTemplate = `
+// AUTO-GENERATED TOC
+// DO NOT EDIT!!!
+package {{.PackageName}}
+
+import (
+	"net/http"
+	"os"
+        embedfs "{{.RuntimeImport}}"
+)
+
+{{if len .Imports }}
+import (
+
+        {{range $alias, $import := .Imports}}
+        {{$alias}} "{{$import}}"
+        {{end}}
+)
+{{end}}
+
+// Fails at init if the runtime can't read this package.
+var _ = embedfs.RequireFormat({{.FormatVersion}})
+
+// Index of every file and directory below this one, sorted by path.
+var DIR = embedfs.EmbedDir{
+	DirName:         {{printf "%q" .DirBaseName}},
+	ModTimeUnixNano: {{.ModTimeUnixNano}},
+	Entries: []embedfs.Entry{ {{range .Entries}}
+		{Path: {{printf "%q" .Path}}, {{if .File}}File: &{{.File}}{{else}}Dir: &{{.Dir}}{{end}}},{{end}}
+	},
+}
+
+// Returns the directory at the given path below this one, or nil if there
+// is none.
+func Dir(path string) http.FileSystem {
+	if fs, err := DIR.Sub(path); err == nil {
+		return fs
+	}
+	return nil
+}
+
+func Mount() http.FileSystem {
+	return Dir(".")
+}
+
+func FileInfo() os.FileInfo {
+	return &DIR
+}
+
+// Returns a copy of the contents of the named file.
+func ReadFile(name string) ([]byte, error) {
+	return DIR.ReadFile(name)
+}
+
+// Returns the contents of the named file without copying.  The slice must
+// not be modified.
+func Bytes(name string) ([]byte, error) {
+	return DIR.Bytes(name)
+}
+
+// Paths of the files in this directory; those below have their own in the
+// packages of their directories.
+const ({{range .Entries}}{{if .Name}}
+	Path{{.Name}} = {{printf "%q" .Path}}{{end}}{{end}}
+)
+{{range .Entries}}{{if .Name}}
+// Returns the contents of {{comment .Path}}, not to be modified.
+func {{.Name}}() []byte {
+	return mustBytes(Path{{.Name}})
+}
+
+// Opens {{comment .Path}}.
+func Open{{.Name}}() http.File {
+	return mustOpen(Path{{.Name}})
+}
+{{end}}{{end}}
+// The files named by the constants are embedded; failing to read one is a
+// bug.
+func mustBytes(name string) []byte {
+	data, err := DIR.Bytes(name)
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
+
+func mustOpen(name string) http.File {
+	f, err := Mount().Open(name)
+	if err != nil {
+		panic(err)
+	}
+	return f
+}
+`
+
+type tocModel struct {
+	RuntimeImport   string
+	FormatVersion   int
+	DirName         string
+	DirBaseName     string
+	PackageName     string
+	ModTimeUnixNano int64
+	Entries         []tocEntry
+	Imports         map[string]string // map[alias]import
+}
+
+func (d *dirToc) writeDirToc(w io.Writer) error {
+	t, err := template.New("dir-toc").Funcs(templateFuncs).Parse(dirTemplate)
+	if err != nil {
+		panic(err)
+	}
+
+	entries, imports, modTime, err := d.buildIndex()
+	if err != nil {
+		return err
+	}
+
+	return t.Execute(w, tocModel{
+		RuntimeImport:   d.g.runtimeImport,
+		FormatVersion:   embedfs.FormatVersion,
+		DirName:         d.dirName,
+		DirBaseName:     filepath.Base(d.dirName),
+		PackageName:     Sanitize(d.dirName),
+		ModTimeUnixNano: modTime,
+		Entries:         entries,
+		Imports:         imports,
+	})
+}
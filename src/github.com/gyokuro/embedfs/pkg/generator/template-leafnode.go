@@ -0,0 +1,145 @@
+package generator
+
+import (
+	"bytes"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/gyokuro/embedfs/pkg/embedfs"
+)
+
+const leafTemplate = `
+// AUTO-GENERATED FROM {{comment .Original}}
+// DO NOT EDIT!!!
+package {{.PackageName}}
+
+import (
+        embedfs "{{.RuntimeImport}}"{{if .Blob}}
+        blobs "{{.BlobImport}}"{{end}}
+)
+{{if not .Blob}}
+const {{.DataName}} = {{.ContentAsString}}
+{{end}}
+var {{.VarName}} = embedfs.EmbedFile{
+	FileName:       {{printf "%q" .BaseName}},
+	Original:   {{printf "%q" .Original}},
+	Compressed: {{.IsCompressed}},
+	Codec:      "{{.Codec}}",
+	ModTimeUnixNano: {{.ModTimeUnixNano}},
+        OriginalSize:     {{.SizeUncompressed}},
+	Data:       {{if .Blob}}blobs.{{.Blob}}{{else}}{{.DataName}}{{end}},
+}
+`
+
+// Contents shared by several files, written once.
+const blobTemplate = `
+// AUTO-GENERATED FROM {{range $i, $f := .Originals}}{{if $i}}, {{end}}{{comment $f}}{{end}}
+// DO NOT EDIT!!!
+package {{.PackageName}}
+
+const {{.Blob}} = {{.ContentAsString}}
+`
+
+type leafModel struct {
+	RuntimeImport    string
+	PackageName      string
+	BaseName         string
+	Original         string
+	VarName          string
+	DataName         string
+	IsCompressed     string
+	Codec            string
+	SizeUncompressed int64
+	ContentAsString  string
+	ModTimeUnixNano  int64
+	Blob             string // the shared constant holding the data, if any
+	BlobImport       string
+}
+
+type blobModel struct {
+	PackageName     string
+	Blob            string
+	Originals       []string
+	ContentAsString string
+}
+
+// Stands in for the data in the rendered template, so that the data can be
+// streamed to the output instead of going through the template engine.
+const contentMarker = "\x00CONTENT\x00"
+
+// Names in string literals are quoted; in comments, quoted only if they
+// would end the comment or make the source invalid, such as those of
+// archive entries holding newlines.
+var templateFuncs = template.FuncMap{"comment": commentText}
+
+func commentText(s string) string {
+	if strconv.CanBackquote(strings.ReplaceAll(s, "`", "")) {
+		return s
+	}
+	return strconv.Quote(s)
+}
+
+var leafTmpl = template.Must(template.New("leafnode").Funcs(templateFuncs).Parse(leafTemplate))
+
+var blobTmpl = template.Must(template.New("blob").Funcs(templateFuncs).Parse(blobTemplate))
+
+func (u *translationUnit) writeLeafNode(w io.Writer) error {
+	var skeleton bytes.Buffer
+	err := leafTmpl.Execute(&skeleton, leafModel{
+		RuntimeImport:    u.g.runtimeImport,
+		PackageName:      u.packageName,
+		BaseName:         u.baseName,
+		Original:         u.src,
+		VarName:          u.name,
+		DataName:         u.dataName,
+		IsCompressed:     strconv.FormatBool(u.codec != embedfs.CodecNone),
+		Codec:            u.codec,
+		SizeUncompressed: u.fileInfo.Size(),
+		ContentAsString:  contentMarker,
+		ModTimeUnixNano:  u.fileInfo.ModTime().UnixNano(),
+		Blob:             u.blob,
+		BlobImport:       path.Join(u.g.importRoot, blobsDir),
+	})
+	if err != nil {
+		return err
+	}
+	if u.blob != "" {
+		_, err = w.Write(skeleton.Bytes())
+		return err
+	}
+	return u.streamData(w, skeleton.Bytes())
+}
+
+// Writes the rendered skeleton with the data in place of contentMarker.
+func (u *translationUnit) streamData(w io.Writer, skeleton []byte) error {
+	head, tail := skeleton, []byte(nil)
+	if i := bytes.Index(head, []byte(contentMarker)); i >= 0 {
+		head, tail = head[:i], head[i+len(contentMarker):]
+	}
+	if _, err := w.Write(head); err != nil {
+		return err
+	}
+	u.writer = w
+	if err := u.writeBinaryRepresentation(); err != nil {
+		return err
+	}
+	_, err := w.Write(tail)
+	return err
+}
+
+// Writes the data of the units, all the same, once as the blob named by
+// the first.
+func writeBlob(w io.Writer, units []*translationUnit) error {
+	model := blobModel{PackageName: Sanitize(blobsDir), Blob: units[0].blob, ContentAsString: contentMarker}
+	for _, u := range units {
+		model.Originals = append(model.Originals, u.src)
+	}
+	var skeleton bytes.Buffer
+	if err := blobTmpl.Execute(&skeleton, model); err != nil {
+		return err
+	}
+	return units[0].streamData(w, skeleton.Bytes())
+}
@@ -0,0 +1,138 @@
+package generator
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// How Watch polls the sources.
+type WatchOptions struct {
+	Interval time.Duration // between polls; a second if zero
+	Debounce time.Duration // without further changes before generating; 200ms if zero
+}
+
+// A generation by Watch.
+type Cycle struct {
+	Report  Report
+	Err     error
+	Changed []string // sources changed since the last generation, on disk or archives, sorted; none for the first
+	Elapsed time.Duration
+}
+
+// Generates everything, then polls the sources until the context is done
+// and, after each burst of changes, generates again the files whose sources
+// changed, appeared or went away, and the TOCs above them.  Files are
+// selected as by Generate, so new files matching Options.Match are picked
+// up.  After every generation, successful or not, calls cycle, and stops
+// with its error if it returns one; sources changed in a failed generation
+// are generated again in the next.
+func Watch(ctx context.Context, opts Options, w WatchOptions, cycle func(Cycle) error) error {
+	if w.Interval <= 0 {
+		w.Interval = time.Second
+	}
+	if w.Debounce <= 0 {
+		w.Debounce = 200 * time.Millisecond
+	}
+	sources := opts.Sources
+	if len(sources) == 0 {
+		sources = []Source{{Path: "."}}
+	}
+
+	last := takeSnapshot(sources)
+	start := time.Now()
+	report, tree, err := generate(ctx, opts, nil, nil)
+	if err := cycle(Cycle{Report: report, Err: err, Changed: []string{}, Elapsed: time.Since(start)}); err != nil {
+		return err
+	}
+	changed := make(map[string]bool)
+	settling := false // after a change, until a poll finds no more
+	for {
+		wait := w.Interval
+		if settling {
+			wait = w.Debounce
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+		snap := takeSnapshot(sources)
+		if last.diff(snap, changed) {
+			last, settling = snap, true
+			continue
+		}
+		if !settling {
+			continue
+		}
+		settling = false
+
+		start := time.Now()
+		report, next, err := generate(ctx, opts, tree, changed)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		c := Cycle{Report: report, Err: err, Changed: []string{}}
+		for source := range changed {
+			c.Changed = append(c.Changed, source)
+		}
+		sort.Strings(c.Changed)
+		if err == nil {
+			tree, changed = next, make(map[string]bool)
+		}
+		c.Elapsed = time.Since(start)
+		if err := cycle(c); err != nil {
+			return err
+		}
+	}
+}
+
+type fileStamp struct {
+	size    int64
+	modTime time.Time
+}
+
+// Sizes and times of the files of the sources, keyed like sourceFile.origin:
+// files on disk by path, archives as a whole.
+type snapshot map[string]fileStamp
+
+// Sources that can't be read, such as a directory being replaced, count as
+// empty.
+func takeSnapshot(sources []Source) snapshot {
+	snap := make(snapshot)
+	for _, src := range sources {
+		stat, err := os.Stat(src.Path)
+		if err != nil {
+			continue
+		}
+		if !stat.IsDir() {
+			snap[src.Path] = fileStamp{stat.Size(), stat.ModTime()}
+			continue
+		}
+		filepath.Walk(src.Path, func(p string, info os.FileInfo, err error) error {
+			if err == nil && info.Mode().IsRegular() {
+				snap[filepath.Clean(p)] = fileStamp{info.Size(), info.ModTime()}
+			}
+			return nil
+		})
+	}
+	return snap
+}
+
+// Adds the sources that differ to changed, returning whether any do.
+func (s snapshot) diff(next snapshot, changed map[string]bool) bool {
+	differ := false
+	for name, stamp := range s {
+		if other, exists := next[name]; !exists || other.size != stamp.size || !other.modTime.Equal(stamp.modTime) {
+			changed[name], differ = true, true
+		}
+	}
+	for name := range next {
+		if _, exists := s[name]; !exists {
+			changed[name], differ = true, true
+		}
+	}
+	return differ
+}
@@ -0,0 +1,86 @@
+package generator
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWatch(t *testing.T) {
+	root := generateSources(t)
+	defer os.RemoveAll(root)
+	dist := filepath.Join(root, "dist")
+	out := newMemOutput()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cycles := make(chan Cycle)
+	done := make(chan error)
+	go func() {
+		done <- Watch(ctx, Options{
+			ImportRoot: "example.com/out",
+			Sources:    []Source{{Path: dist, Virtual: "site"}},
+			Match:      regexp.MustCompile(`\.(html|css|js|png)$`),
+			Output:     out,
+		}, WatchOptions{Interval: 10 * time.Millisecond, Debounce: 30 * time.Millisecond}, func(c Cycle) error {
+			cycles <- c
+			return nil
+		})
+	}()
+	next := func() Cycle {
+		t.Helper()
+		select {
+		case c := <-cycles:
+			if c.Err != nil {
+				t.Fatal(c.Err)
+			}
+			return c
+		case <-time.After(5 * time.Second):
+			t.Fatal("no cycle")
+		}
+		return Cycle{}
+	}
+	generated := func(c Cycle) string {
+		return strings.Join(c.Report.GoFiles, ",")
+	}
+
+	if c := next(); len(c.Report.Files) != len(siteFiles) {
+		t.Errorf("first cycle embedded %d files", len(c.Report.Files))
+	}
+
+	// A burst of changes makes one cycle.
+	later := time.Now().Add(time.Hour)
+	writeFiles(t, dist, map[string]string{"js/lib/jquery.js": "jQuery 2", "js/new.js": "new", "js/new.txt": "not selected"})
+	os.Chtimes(filepath.Join(dist, "js/lib/jquery.js"), later, later)
+	c := next()
+	if got := generated(c); got != "site/generated-toc.go,site/js/generated-toc.go,site/js/lib/generated-toc.go,"+
+		"site/js/lib/jquery.js.go,site/js/new.js.go" {
+		t.Errorf("generated %s", got)
+	}
+	if len(c.Changed) != 3 {
+		t.Errorf("changed %v", c.Changed)
+	}
+
+	// Files removed take their generated files with them, and TOCs of
+	// directories left empty.
+	os.RemoveAll(filepath.Join(dist, "img"))
+	c = next()
+	if got := generated(c); got != "site/generated-toc.go" {
+		t.Errorf("generated %s", got)
+	}
+	if got := strings.Join(c.Report.Removed, ","); got != "site/img/generated-toc.go,site/img/icons/a/b.png.go,"+
+		"site/img/icons/a/generated-toc.go,site/img/icons/generated-toc.go" {
+		t.Errorf("removed %s", got)
+	}
+	if out.contains(t, "site/generated-toc.go", "img") {
+		t.Error("site/generated-toc.go still indexes img")
+	}
+
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("Watch returned %v", err)
+	}
+}
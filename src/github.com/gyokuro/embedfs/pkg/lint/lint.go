@@ -0,0 +1,480 @@
+// Package lint finds the paths given as constants to generated embedfs
+// packages that aren't in their embedded trees, which would otherwise only
+// show at run time, as errors or 404s.
+package lint
+
+import (
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/constant"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template/parse"
+
+	"github.com/gyokuro/embedfs/pkg/embedfs"
+)
+
+// What is checked besides the calls into generated packages.
+type Options struct {
+	// Import path of the generated package that the arguments of calls to
+	// functions named AssetPath are relative to; empty to leave them out.
+	Assets string
+
+	// Template files whose {{AssetPath "..."}} arguments are checked
+	// against Assets too.
+	Templates []string
+}
+
+// A path not in the tree it is looked up in.
+type Problem struct {
+	Pos     token.Position
+	Package string // import path of the generated package
+	Path    string
+	Message string
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("%s: %s", p.Pos, p.Message)
+}
+
+// Checks the packages in the directories, a trailing /... taking those
+// below too, and the templates, and returns the problems found sorted by
+// position.  Imports are found as by go/build.Default.
+//
+// Constant paths, literals or named constants, are checked where they are
+// given to the generated package directly: Dir, ReadFile and Bytes, the
+// Sub, ReadFile and Bytes methods of its DIR, and Open on the file system
+// returned by its Mount or Dir, wherever that is used, in http.FileServer or
+// elsewhere.  File systems kept in variables are not followed.
+func Check(patterns []string, opts Options) ([]Problem, error) {
+	fset := token.NewFileSet()
+	c := &checker{
+		opts:     opts,
+		fset:     fset,
+		importer: importer.ForCompiler(fset, "source", nil),
+		trees:    make(map[string]tree),
+	}
+	if opts.Assets != "" && c.treeOf(opts.Assets) == nil {
+		return nil, fmt.Errorf("%s is not a generated package", opts.Assets)
+	}
+	dirs, err := expand(patterns)
+	if err != nil {
+		return nil, err
+	}
+	for _, dir := range dirs {
+		if err := c.checkDir(dir); err != nil {
+			return nil, err
+		}
+	}
+	for _, name := range opts.Templates {
+		if err := c.checkTemplate(name); err != nil {
+			return nil, err
+		}
+	}
+	sort.Slice(c.problems, func(i, j int) bool {
+		a, b := c.problems[i].Pos, c.problems[j].Pos
+		if a.Filename != b.Filename {
+			return a.Filename < b.Filename
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+	return c.problems, nil
+}
+
+// Returns the directories matched, those holding Go files for patterns
+// ending in /...; testdata and directories starting with . or _ are
+// skipped, as by the go command.
+func expand(patterns []string) ([]string, error) {
+	dirs := []string{}
+	for _, pattern := range patterns {
+		if !strings.HasSuffix(pattern, "...") {
+			dirs = append(dirs, pattern)
+			continue
+		}
+		root := filepath.Clean(strings.TrimSuffix(strings.TrimSuffix(pattern, "..."), "/"))
+		err := filepath.Walk(root, func(p string, info os.FileInfo, err error) error {
+			if err != nil || !info.IsDir() {
+				return err
+			}
+			name := info.Name()
+			if p != root && (name == "testdata" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+				return filepath.SkipDir
+			}
+			if matches, _ := filepath.Glob(filepath.Join(p, "*.go")); len(matches) > 0 {
+				dirs = append(dirs, p)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return dirs, nil
+}
+
+// Paths of a generated package's tree, true for directories.
+type tree map[string]bool
+
+type checker struct {
+	opts     Options
+	fset     *token.FileSet
+	importer types.Importer  // shared, so that packages are checked once
+	trees    map[string]tree // by import path; nil for packages that aren't generated
+	problems []Problem
+}
+
+// Type checks the package in the directory, with its tests, then its
+// external tests, and checks their calls.
+func (c *checker) checkDir(dir string) error {
+	pkg, err := build.Default.ImportDir(dir, 0)
+	if _, noGo := err.(*build.NoGoError); noGo {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	for _, names := range [][]string{append(pkg.GoFiles, pkg.TestGoFiles...), pkg.XTestGoFiles} {
+		if len(names) == 0 {
+			continue
+		}
+		files := []*ast.File{}
+		for _, name := range names {
+			f, err := parser.ParseFile(c.fset, filepath.Join(dir, name), nil, 0)
+			if err != nil {
+				return err
+			}
+			files = append(files, f)
+		}
+		info := &types.Info{
+			Types: make(map[ast.Expr]types.TypeAndValue),
+			Uses:  make(map[*ast.Ident]types.Object),
+		}
+		config := &types.Config{
+			Importer: c.importer,
+			Error:    func(error) {}, // check what can be
+		}
+		config.Check(pkg.ImportPath, c.fset, files, info)
+		for _, f := range files {
+			ast.Inspect(f, func(n ast.Node) bool {
+				if call, ok := n.(*ast.CallExpr); ok {
+					c.checkCall(call, info)
+				}
+				return true
+			})
+		}
+	}
+	return nil
+}
+
+// What a call expects of its path argument.
+type expect int
+
+const (
+	expectAny expect = iota
+	expectFile
+	expectDir
+)
+
+func (c *checker) checkCall(call *ast.CallExpr, info *types.Info) {
+	if len(call.Args) != 1 {
+		return
+	}
+	fn := callee(call, info)
+	if fn == nil {
+		return
+	}
+
+	if fn.Name() == "AssetPath" && c.opts.Assets != "" {
+		if p, ok := constantString(call.Args[0], info); ok {
+			c.checkPath(call.Args[0].Pos(), c.opts.Assets, "", p, expectFile)
+		}
+		return
+	}
+
+	// Functions of a generated package
+	if sig := fn.Type().(*types.Signature); sig.Recv() == nil {
+		if fn.Pkg() == nil || c.tree(fn.Pkg()) == nil {
+			return
+		}
+		want := map[string]expect{"Dir": expectDir, "ReadFile": expectFile, "Bytes": expectFile}
+		if e, ok := want[fn.Name()]; ok {
+			if p, ok := constantString(call.Args[0], info); ok {
+				c.checkPath(call.Args[0].Pos(), fn.Pkg().Path(), "", p, e)
+			}
+		}
+		return
+	}
+	sel, isSel := call.Fun.(*ast.SelectorExpr)
+	if !isSel {
+		return
+	}
+
+	// Methods of DIR, and Open on what Mount and Dir return
+	pkg, base, viaDIR, ok := c.root(sel.X, info)
+	if !ok {
+		return
+	}
+	want := map[string]expect{"Open": expectAny}
+	if viaDIR {
+		want = map[string]expect{"Sub": expectDir, "ReadFile": expectFile, "Bytes": expectFile}
+	}
+	if e, ok := want[fn.Name()]; ok {
+		if p, ok := constantString(call.Args[0], info); ok {
+			c.checkPath(call.Args[0].Pos(), pkg, base, p, e)
+		}
+	}
+}
+
+// Resolves an expression for a directory of a generated package: its DIR,
+// a call to its Mount, or to its Dir with a constant path of a directory.
+// Returns the package, the directory, "." for the root, and whether the
+// expression is DIR.
+func (c *checker) root(x ast.Expr, info *types.Info) (pkg string, dir string, viaDIR bool, ok bool) {
+	switch x := x.(type) {
+	case *ast.ParenExpr:
+		return c.root(x.X, info)
+	case *ast.SelectorExpr:
+		if v, isVar := info.Uses[x.Sel].(*types.Var); isVar && v.Name() == "DIR" && v.Pkg() != nil &&
+			c.tree(v.Pkg()) != nil {
+			return v.Pkg().Path(), ".", true, true
+		}
+	case *ast.CallExpr:
+		fn := callee(x, info)
+		if fn == nil || fn.Pkg() == nil || c.tree(fn.Pkg()) == nil {
+			return "", "", false, false
+		}
+		switch {
+		case fn.Name() == "Mount" && len(x.Args) == 0:
+			return fn.Pkg().Path(), ".", false, true
+		case fn.Name() == "Dir" && len(x.Args) == 1:
+			// A directory not in the tree is reported once, for Dir.
+			p, isConst := constantString(x.Args[0], info)
+			if !isConst {
+				break
+			}
+			dir, err := embedfs.CleanPath(p)
+			if err == nil && (dir == "." || c.tree(fn.Pkg())[dir]) {
+				return fn.Pkg().Path(), dir, false, true
+			}
+		}
+	}
+	return "", "", false, false
+}
+
+// Returns the function or method called, nil for other calls.
+func callee(call *ast.CallExpr, info *types.Info) *types.Func {
+	var fn *types.Func
+	switch fun := call.Fun.(type) {
+	case *ast.Ident:
+		fn, _ = info.Uses[fun].(*types.Func)
+	case *ast.SelectorExpr:
+		fn, _ = info.Uses[fun.Sel].(*types.Func)
+	}
+	return fn
+}
+
+func constantString(x ast.Expr, info *types.Info) (string, bool) {
+	tv, ok := info.Types[x]
+	if !ok || tv.Value == nil || tv.Value.Kind() != constant.String {
+		return "", false
+	}
+	return constant.StringVal(tv.Value), true
+}
+
+// Reports the path, relative to the directory base of the package, unless
+// it is in its tree as expected.  Paths are cleaned as the runtime does.
+func (c *checker) checkPath(pos token.Pos, pkg, base, p string, e expect) {
+	t := c.treeOf(pkg)
+	if t == nil {
+		return
+	}
+	full := p
+	clean, err := embedfs.CleanPath(p)
+	if err == nil {
+		full = path.Join(base, clean)
+	}
+	problem := func(format string, args ...interface{}) {
+		c.problems = append(c.problems, Problem{
+			Pos:     c.fset.Position(pos),
+			Package: pkg,
+			Path:    full,
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+	if err != nil {
+		problem("%s is not a valid path", strconv.Quote(p))
+		return
+	}
+	if full == "." {
+		if e == expectFile {
+			problem("%s is the root of %s, not a file", strconv.Quote(p), pkg)
+		}
+		return
+	}
+	isDir, exists := t[full]
+	switch {
+	case !exists:
+		problem("%s is not in %s", strconv.Quote(full), pkg)
+	case e == expectFile && isDir:
+		problem("%s is a directory of %s, not a file", strconv.Quote(full), pkg)
+	case e == expectDir && !isDir:
+		problem("%s is a file of %s, not a directory", strconv.Quote(full), pkg)
+	}
+}
+
+// Returns the tree of a generated package, nil for other packages.  A
+// package is taken for generated if it has a DIR of type EmbedDir.
+func (c *checker) tree(pkg *types.Package) tree {
+	if t, done := c.trees[pkg.Path()]; done {
+		return t
+	}
+	v, ok := pkg.Scope().Lookup("DIR").(*types.Var)
+	if named, isNamed := typeOf(v).(*types.Named); !ok || !isNamed || named.Obj().Name() != "EmbedDir" {
+		c.trees[pkg.Path()] = nil
+		return nil
+	}
+	return c.treeOf(pkg.Path())
+}
+
+func typeOf(v *types.Var) types.Type {
+	if v == nil {
+		return nil
+	}
+	return v.Type()
+}
+
+// Reads the tree of a generated package from the index in its TOC.  Paths
+// of packages that can't be read have a nil tree.
+func (c *checker) treeOf(importPath string) tree {
+	if t, done := c.trees[importPath]; done {
+		return t
+	}
+	c.trees[importPath] = nil
+	pkg, err := build.Default.Import(importPath, ".", build.FindOnly)
+	if err != nil {
+		return nil
+	}
+	f, err := parser.ParseFile(token.NewFileSet(), filepath.Join(pkg.Dir, "generated-toc.go"), nil, 0)
+	if err != nil {
+		return nil
+	}
+	t := make(tree)
+	ast.Inspect(f, func(n ast.Node) bool {
+		entry, ok := n.(*ast.CompositeLit)
+		if !ok {
+			return true
+		}
+		var p string
+		isDir, isEntry := false, false
+		for _, elt := range entry.Elts {
+			kv, ok := elt.(*ast.KeyValueExpr)
+			if !ok {
+				continue
+			}
+			switch key, _ := kv.Key.(*ast.Ident); {
+			case key == nil:
+			case key.Name == "Path":
+				if lit, ok := kv.Value.(*ast.BasicLit); ok && lit.Kind == token.STRING {
+					p, _ = strconv.Unquote(lit.Value)
+					isEntry = true
+				}
+			case key.Name == "Dir":
+				isDir = true
+			}
+		}
+		if isEntry {
+			t[p] = isDir
+		}
+		return true
+	})
+	c.trees[importPath] = t
+	return t
+}
+
+// Checks the constant arguments of AssetPath in a template.
+func (c *checker) checkTemplate(name string) error {
+	if c.opts.Assets == "" {
+		return fmt.Errorf("%s: no -assets package to check AssetPath against", name)
+	}
+	data, err := ioutil.ReadFile(name)
+	if err != nil {
+		return err
+	}
+	file := c.fset.AddFile(name, -1, len(data))
+	file.SetLinesForContent(data)
+
+	t := parse.New(name)
+	t.Mode = parse.SkipFuncCheck
+	trees := make(map[string]*parse.Tree)
+	if _, err := t.Parse(string(data), "", "", trees); err != nil {
+		return err
+	}
+	for _, tr := range trees {
+		walkTemplate(tr.Root, func(n *parse.CommandNode) {
+			if len(n.Args) != 2 {
+				return
+			}
+			fn, ok := n.Args[0].(*parse.IdentifierNode)
+			lit, isString := n.Args[1].(*parse.StringNode)
+			if ok && isString && fn.Ident == "AssetPath" {
+				c.checkPath(file.Pos(int(lit.Pos)), c.opts.Assets, "", lit.Text, expectFile)
+			}
+		})
+	}
+	return nil
+}
+
+// Calls visit for every command in the template, in pipelines nested in
+// actions, conditions and loops too.
+func walkTemplate(n parse.Node, visit func(*parse.CommandNode)) {
+	var pipe func(*parse.PipeNode)
+	pipe = func(p *parse.PipeNode) {
+		if p == nil {
+			return
+		}
+		for _, cmd := range p.Cmds {
+			visit(cmd)
+			for _, arg := range cmd.Args {
+				if nested, ok := arg.(*parse.PipeNode); ok {
+					pipe(nested)
+				}
+			}
+		}
+	}
+	switch n := n.(type) {
+	case *parse.ListNode:
+		if n != nil {
+			for _, child := range n.Nodes {
+				walkTemplate(child, visit)
+			}
+		}
+	case *parse.ActionNode:
+		pipe(n.Pipe)
+	case *parse.IfNode:
+		pipe(n.Pipe)
+		walkTemplate(n.List, visit)
+		walkTemplate(n.ElseList, visit)
+	case *parse.RangeNode:
+		pipe(n.Pipe)
+		walkTemplate(n.List, visit)
+		walkTemplate(n.ElseList, visit)
+	case *parse.WithNode:
+		pipe(n.Pipe)
+		walkTemplate(n.List, visit)
+		walkTemplate(n.ElseList, visit)
+	case *parse.TemplateNode:
+		pipe(n.Pipe)
+	}
+}
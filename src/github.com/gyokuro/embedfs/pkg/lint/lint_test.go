@@ -0,0 +1,181 @@
+package lint
+
+import (
+	"context"
+	"go/build"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gyokuro/embedfs/pkg/generator"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+const client = `package main
+
+import (
+	"net/http"
+
+	"lintsite/gen/site"
+)
+
+const missing = "img/missing.png"
+
+func AssetPath(p string) string { return p }
+
+func main() {
+	site.Mount().Open("index.html")
+	site.Mount().Open("index.htm")
+	site.Dir("css").Open("style.css")
+	site.Dir("css").Open("/../css/style.css")
+	site.Dir("index.html")
+	site.ReadFile("css")
+	site.Bytes(missing)
+	site.Bytes(site.PathIndexHtml)
+	site.DIR.Sub("img")
+	site.DIR.Bytes("img/logo.svg")
+	http.Handle("/", http.FileServer(site.Dir("images")))
+	AssetPath("css/style.css")
+	AssetPath("css/site.css")
+	site.Dir("/css").Open("vendor/bootstrap.css")
+	site.Dir("/css/").Open("vendor/missing.css")
+}
+`
+
+// Generates lintsite/gen/site in a temporary GOPATH put first in
+// build.Default.  Returns the directory of lintsite and a function that
+// undoes it all.
+func lintSite(t *testing.T) (root string, cleanup func()) {
+	gopath, err := ioutil.TempDir("", "embedfs-lint")
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved := build.Default.GOPATH
+	cleanup = func() {
+		build.Default.GOPATH = saved
+		os.RemoveAll(gopath)
+	}
+	build.Default.GOPATH = gopath + string(filepath.ListSeparator) + build.Default.GOPATH
+
+	root = filepath.Join(gopath, "src", "lintsite")
+	writeFiles(t, filepath.Join(root, "assets"), map[string]string{
+		"index.html":               "<html></html>",
+		"css/style.css":            "body {}",
+		"css/vendor/bootstrap.css": "body {}",
+		"img/logo.png":             "\x89PNG",
+	})
+	_, err = generator.Generate(context.Background(), generator.Options{
+		DestDir:        filepath.Join(root, "gen"),
+		ImportRoot:     "lintsite/gen",
+		Sources:        []generator.Source{{Path: filepath.Join(root, "assets"), Virtual: "site"}},
+		RuntimePackage: generator.RuntimePackage,
+	})
+	if err != nil {
+		cleanup()
+		t.Fatal(err)
+	}
+	return root, cleanup
+}
+
+func TestCheck(t *testing.T) {
+	root, cleanup := lintSite(t)
+	defer cleanup()
+	writeFiles(t, root, map[string]string{
+		"app/main.go":         client,
+		"templates/page.html": `{{if .}}<img src="{{AssetPath "img/logo.png"}}">{{AssetPath "img/logo.gif" | html}}{{end}}`,
+	})
+
+	problems, err := Check([]string{filepath.Join(root, "...")}, Options{
+		Assets:    "lintsite/gen/site",
+		Templates: []string{filepath.Join(root, "templates", "page.html")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := []string{}
+	for _, p := range problems {
+		rel, _ := filepath.Rel(root, p.Pos.Filename)
+		p.Pos.Filename = filepath.ToSlash(rel)
+		got = append(got, p.String())
+	}
+	want := []string{
+		`app/main.go:15:20: "index.htm" is not in lintsite/gen/site`,
+		`app/main.go:17:23: "/../css/style.css" is not a valid path`,
+		`app/main.go:18:11: "index.html" is a file of lintsite/gen/site, not a directory`,
+		`app/main.go:19:16: "css" is a directory of lintsite/gen/site, not a file`,
+		`app/main.go:20:13: "img/missing.png" is not in lintsite/gen/site`,
+		`app/main.go:23:17: "img/logo.svg" is not in lintsite/gen/site`,
+		`app/main.go:24:44: "images" is not in lintsite/gen/site`,
+		`app/main.go:26:12: "css/site.css" is not in lintsite/gen/site`,
+		`app/main.go:28:25: "css/vendor/missing.css" is not in lintsite/gen/site`,
+		`templates/page.html:1:61: "img/logo.gif" is not in lintsite/gen/site`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("problems:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	if _, err := Check(nil, Options{Assets: "lintsite/assets"}); err == nil {
+		t.Error("assets not generated accepted")
+	}
+}
+
+// Paths are cleaned as the runtime's Open and Dir clean them.
+func TestCheckPaths(t *testing.T) {
+	root, cleanup := lintSite(t)
+	defer cleanup()
+
+	cases := []struct {
+		call, problem string
+	}{
+		{`site.Mount().Open("/css/style.css")`, ""},
+		{`site.Mount().Open("//css/style.css")`, ""},
+		{`site.Mount().Open("css//style.css")`, ""},
+		{`site.Mount().Open("css/./vendor/../style.css")`, ""},
+		{`site.Mount().Open("//css/missing.css")`, `"css/missing.css" is not in lintsite/gen/site`},
+		{`site.Mount().Open("css/../../css/style.css")`, `"css/../../css/style.css" is not a valid path`},
+		{`site.Mount().Open("/../index.html")`, `"/../index.html" is not a valid path`},
+		{`site.Mount().Open("css\\style.css")`, `"css\\style.css" is not a valid path`},
+		{`site.Dir("//css").Open("style.css")`, ""},
+		{`site.Dir("/css//vendor/").Open("//bootstrap.css")`, ""},
+		{`site.Dir("css/vendor/..").Open("vendor/missing.css")`, `"css/vendor/missing.css" is not in lintsite/gen/site`},
+		{`site.Dir("css").Open("../index.html")`, `"../index.html" is not a valid path`},
+		{`site.Dir("/..")`, `"/.." is not a valid path`},
+	}
+	// One call a line, from line 6 on.
+	source := "package main\n\nimport \"lintsite/gen/site\"\n\nfunc main() {\n"
+	for _, c := range cases {
+		source += "\t" + c.call + "\n"
+	}
+	writeFiles(t, root, map[string]string{"app/main.go": source + "}\n"})
+
+	problems, err := Check([]string{filepath.Join(root, "app")}, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make([]string, len(cases))
+	for _, p := range problems {
+		if i := p.Pos.Line - 6; i >= 0 && i < len(cases) && got[i] == "" {
+			got[i] = p.Message
+		} else {
+			t.Errorf("unexpected problem %s", p)
+		}
+	}
+	for i, c := range cases {
+		if got[i] != c.problem {
+			t.Errorf("%s: got %q, want %q", c.call, got[i], c.problem)
+		}
+	}
+}
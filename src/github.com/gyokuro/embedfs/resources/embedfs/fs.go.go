@@ -3,128 +3,17 @@
 package embedfs
 
 import (
-	"time"
 	embedfs "github.com/gyokuro/embedfs/resources"
 )
 
-var fs_go = embedfs.EmbedFile{
-	FileName:         "fs.go",
-	Original:         "embedfs/fs.go",
-	Compressed:       true,
-	ModificationTime: time.Unix(1376258896, 1376258896000000000),
-	OriginalSize:     5457,
-	Data: []byte{
-		0x78, 0x9c, 0xac, 0x58, 0x5b, 0x6f, 0x9b, 0x48, 0x14, 0x7e, 0x36, 0xbf, 0x62, 0xd6, 0x0f, 0x15,
-		0x6c, 0x5c, 0x92, 0x87, 0x68, 0x1f, 0xdc, 0xba, 0x52, 0x36, 0x71, 0x77, 0xa3, 0x6d, 0x13, 0xc9,
-		0x6e, 0x1f, 0x56, 0x91, 0x15, 0x61, 0x18, 0x02, 0x0a, 0x1e, 0x10, 0x8c, 0x9b, 0xba, 0xd9, 0xfc,
-		0xf7, 0x3d, 0x97, 0x01, 0x06, 0x63, 0xdc, 0x56, 0xaa, 0xd5, 0xfa, 0x32, 0x73, 0xce, 0x77, 0xee,
-		0x17, 0x52, 0x04, 0xe1, 0x63, 0xf0, 0x20, 0x85, 0xdc, 0xac, 0x65, 0x14, 0x57, 0x8e, 0x93, 0x6e,
-		0x8a, 0xbc, 0xd4, 0xc2, 0x75, 0x46, 0xe3, 0xf5, 0x4e, 0xcb, 0x6a, 0x0c, 0x5f, 0xc2, 0x7c, 0x53,
-		0x94, 0xb2, 0xaa, 0x4e, 0xbf, 0x65, 0xe9, 0x1a, 0x0f, 0x64, 0x59, 0xe6, 0x25, 0x5d, 0xa5, 0x39,
-		0xbe, 0x2b, 0xa9, 0x4f, 0x13, 0xad, 0x0b, 0xfc, 0x9e, 0xd3, 0x79, 0x11, 0xe8, 0xe4, 0x34, 0x4e,
-		0x33, 0x89, 0x5f, 0xf0, 0xa0, 0xd2, 0x65, 0xaa, 0x1e, 0xe8, 0xae, 0xda, 0xa9, 0x10, 0x3f, 0x75,
-		0xba, 0x91, 0x63, 0xc7, 0x73, 0x9c, 0x78, 0xab, 0x42, 0x71, 0x95, 0x96, 0x17, 0x59, 0x96, 0x87,
-		0xae, 0x0a, 0x36, 0x52, 0x30, 0xb9, 0x27, 0x7e, 0xbf, 0x8f, 0xd2, 0x52, 0x3c, 0x3b, 0xa3, 0x52,
-		0xea, 0x6d, 0xa9, 0xc4, 0x2b, 0xfc, 0x0d, 0x3f, 0x47, 0x48, 0x35, 0x15, 0xf0, 0xc2, 0x2f, 0x13,
-		0x38, 0xd8, 0xe4, 0xd1, 0xa7, 0x14, 0xcf, 0x10, 0xd6, 0xbf, 0xc9, 0x9f, 0x5c, 0x0f, 0x8f, 0x81,
-		0xbc, 0x22, 0xba, 0x4d, 0xf0, 0x28, 0xdd, 0x4d, 0x50, 0xdc, 0x31, 0xf4, 0x8a, 0x90, 0x89, 0x04,
-		0xd5, 0x24, 0x9a, 0x1e, 0xc9, 0x1c, 0xbd, 0xf2, 0x1e, 0xae, 0x91, 0xee, 0xc5, 0x79, 0x71, 0x9c,
-		0xd3, 0x53, 0x81, 0x86, 0xfa, 0x78, 0xb8, 0xdc, 0x55, 0x5a, 0x6e, 0xf0, 0x48, 0xef, 0x0a, 0x29,
-		0xda, 0x23, 0x91, 0x2a, 0x2d, 0xcb, 0x38, 0x08, 0x25, 0x68, 0x0e, 0xd7, 0xa3, 0xdb, 0x42, 0xaa,
-		0xae, 0x5d, 0x2e, 0x52, 0x4f, 0x04, 0x39, 0xd2, 0x43, 0x9a, 0x17, 0x78, 0xeb, 0xa0, 0x77, 0x70,
-		0x7b, 0x88, 0x97, 0x59, 0x5e, 0x49, 0xd7, 0x63, 0x00, 0x3a, 0x59, 0xea, 0x40, 0xc3, 0x81, 0x9b,
-		0x57, 0xc4, 0x7e, 0xad, 0xe2, 0xdc, 0xc6, 0x1f, 0x2d, 0x64, 0x10, 0x81, 0xc5, 0x6e, 0x98, 0x6f,
-		0x95, 0x46, 0x3c, 0xa0, 0xbd, 0x5b, 0x1d, 0xa3, 0x86, 0x6b, 0x4c, 0x01, 0xa0, 0x03, 0xea, 0xce,
-		0xed, 0x52, 0xca, 0x47, 0x37, 0x8f, 0xe3, 0x4a, 0x12, 0xd2, 0x1f, 0xe7, 0x13, 0xf1, 0x94, 0x48,
-		0x15, 0x4a, 0x83, 0x6b, 0xce, 0xfa, 0xd6, 0x59, 0xe2, 0x3a, 0xf6, 0xe1, 0x41, 0xcf, 0xc6, 0x1b,
-		0x70, 0x18, 0x58, 0xc4, 0x2e, 0x13, 0xfc, 0x82, 0xf3, 0x75, 0x50, 0x49, 0x8a, 0xba, 0xc8, 0x63,
-		0xa1, 0x13, 0x29, 0x62, 0xe3, 0xad, 0xd1, 0x32, 0xfd, 0x86, 0x0c, 0x24, 0x5d, 0xb4, 0x0c, 0x99,
-		0x54, 0x0f, 0x3a, 0x81, 0x63, 0x41, 0x19, 0x2d, 0xe2, 0xbc, 0x14, 0xa5, 0x7c, 0xd8, 0x66, 0x41,
-		0x49, 0xbc, 0xd5, 0x1b, 0x51, 0x51, 0xdc, 0x5e, 0x47, 0x12, 0x02, 0x15, 0x49, 0xf0, 0x0f, 0xd2,
-		0xe4, 0x00, 0x5e, 0x56, 0x84, 0xfc, 0x31, 0x8f, 0x10, 0x19, 0x55, 0xc5, 0xaf, 0x35, 0x32, 0x72,
-		0x8b, 0x0d, 0x1e, 0xac, 0x53, 0xdd, 0x50, 0x62, 0x1a, 0x02, 0x31, 0xe5, 0x21, 0x7e, 0x47, 0x4a,
-		0x20, 0x4a, 0xe3, 0x34, 0x0c, 0x74, 0x9a, 0x2b, 0xba, 0x21, 0xe2, 0xeb, 0x0a, 0xb2, 0x1e, 0x48,
-		0xd7, 0x79, 0x9e, 0x59, 0x0a, 0x07, 0xeb, 0x75, 0x29, 0xbf, 0xa4, 0x4c, 0x8c, 0x9a, 0xb0, 0x78,
-		0xdf, 0x90, 0xb3, 0xad, 0xbb, 0x8a, 0x4d, 0x65, 0x97, 0x3d, 0xbf, 0x30, 0xeb, 0x16, 0xd4, 0x2f,
-		0xb3, 0x1d, 0x3a, 0x2c, 0x0a, 0x74, 0x20, 0xaa, 0x7c, 0x5b, 0x82, 0x43, 0xdd, 0x30, 0x50, 0xc2,
-		0x94, 0x90, 0x4a, 0x33, 0x13, 0x13, 0x7c, 0x9f, 0xab, 0x6a, 0x0b, 0xb5, 0x2d, 0x8a, 0x32, 0x2f,
-		0x64, 0x29, 0xa0, 0xfc, 0x33, 0xb9, 0x01, 0x0f, 0xb0, 0x70, 0xf0, 0x70, 0x23, 0xa2, 0x72, 0xbe,
-		0x80, 0xc3, 0xee, 0xf7, 0xf3, 0x5f, 0xcc, 0x84, 0x4b, 0xb5, 0xf4, 0x77, 0xa0, 0x22, 0xa8, 0x14,
-		0x97, 0xf0, 0xf7, 0x48, 0x89, 0x08, 0xbd, 0x75, 0x80, 0xc8, 0xca, 0x89, 0x06, 0xeb, 0x28, 0x41,
-		0x5b, 0x95, 0x4c, 0x05, 0x66, 0xfc, 0x9a, 0x17, 0xfa, 0xe3, 0xea, 0x7a, 0x31, 0xbf, 0xfc, 0x74,
-		0xbb, 0xf8, 0xd7, 0x71, 0x28, 0x3b, 0xa9, 0xff, 0x40, 0x0a, 0x6e, 0x43, 0x8d, 0x6d, 0x88, 0xf2,
-		0x0e, 0x5e, 0x9c, 0x94, 0x4e, 0xdd, 0x75, 0xda, 0x60, 0x3b, 0xdc, 0x4e, 0xa8, 0x9b, 0x1c, 0x68,
-		0x24, 0x0e, 0x35, 0x24, 0xb1, 0x77, 0x8d, 0x42, 0x9c, 0x11, 0xf6, 0x45, 0xc2, 0x86, 0x4f, 0xff,
-		0xe3, 0x56, 0xcb, 0xaf, 0xd8, 0x71, 0xa8, 0x39, 0xba, 0x11, 0x77, 0x42, 0x4f, 0x74, 0x8b, 0xa2,
-		0x6d, 0x8c, 0x91, 0x8f, 0xaa, 0x01, 0xc3, 0x1e, 0x7d, 0xa7, 0x26, 0x5a, 0xf2, 0xb3, 0x3e, 0xa5,
-		0xc9, 0x71, 0xe3, 0x6d, 0x4a, 0x73, 0x8b, 0xfe, 0xfc, 0xfc, 0x5c, 0xfc, 0x87, 0x97, 0x78, 0x01,
-		0x69, 0x78, 0x90, 0xbf, 0x97, 0xf9, 0xb6, 0x7e, 0xc6, 0x55, 0x7d, 0xc6, 0x4e, 0x15, 0xb4, 0x1c,
-		0xe0, 0xf3, 0x43, 0xf6, 0xf4, 0xf2, 0xbe, 0xe5, 0x80, 0x6c, 0xe8, 0x33, 0x50, 0xef, 0xf5, 0x3a,
-		0x39, 0x5a, 0x77, 0x26, 0x64, 0xe5, 0x68, 0x4d, 0x67, 0xdc, 0xfc, 0xf7, 0x1a, 0xe2, 0x99, 0x07,
-		0x04, 0x50, 0x7f, 0xf7, 0x13, 0x81, 0x69, 0x00, 0x54, 0x65, 0xa0, 0x60, 0x5a, 0x46, 0x3e, 0x45,
-		0x11, 0xe7, 0x50, 0x1a, 0xe3, 0x15, 0x39, 0x5f, 0xfc, 0x36, 0x33, 0x61, 0xa0, 0x1b, 0x03, 0x3d,
-		0x13, 0x41, 0x81, 0x5d, 0xc5, 0xa5, 0x9f, 0x04, 0x04, 0xa8, 0x30, 0x4d, 0xf0, 0x9f, 0x01, 0xa7,
-		0x3e, 0x62, 0xa1, 0x33, 0xe7, 0xb3, 0x33, 0x84, 0x81, 0x1f, 0x1e, 0x01, 0xd8, 0x63, 0x91, 0x8d,
-		0x43, 0xae, 0x0a, 0xca, 0x17, 0x06, 0x5a, 0x64, 0x4d, 0x37, 0xe6, 0x04, 0x9e, 0x89, 0x71, 0x53,
-		0xed, 0x27, 0xb0, 0xcb, 0x78, 0xea, 0x22, 0xa2, 0x14, 0x25, 0x21, 0xc2, 0xaa, 0x32, 0x54, 0x04,
-		0x6d, 0xa4, 0xbc, 0xfc, 0x90, 0x87, 0x8f, 0xd0, 0x82, 0xe8, 0x80, 0x30, 0xef, 0xf0, 0x9d, 0x7c,
-		0x86, 0xa9, 0xb9, 0x02, 0x5d, 0xe3, 0x3a, 0xcf, 0x99, 0xe3, 0xb3, 0xca, 0x98, 0x67, 0x40, 0x26,
-		0x06, 0xbf, 0xda, 0xae, 0xad, 0xc3, 0x21, 0x79, 0xe8, 0xf4, 0x3b, 0x26, 0x25, 0x37, 0xa3, 0x30,
-		0xfe, 0x39, 0x20, 0xae, 0xa9, 0x60, 0x76, 0x8d, 0x55, 0xc7, 0xe8, 0x21, 0x28, 0x35, 0x53, 0x79,
-		0xed, 0x28, 0x6b, 0xca, 0xb7, 0x93, 0x09, 0xd4, 0xeb, 0x73, 0xab, 0x3b, 0x62, 0xed, 0x99, 0x81,
-		0xda, 0xab, 0x51, 0xd3, 0xe2, 0xc4, 0x81, 0xa1, 0x9f, 0xb0, 0x1a, 0x4d, 0x5b, 0xa4, 0x44, 0xb4,
-		0x92, 0x91, 0xa8, 0xd9, 0x81, 0xb8, 0x37, 0xf9, 0x97, 0x99, 0x0c, 0x18, 0x03, 0x3c, 0x00, 0x99,
-		0xd6, 0x5c, 0x5c, 0x57, 0x17, 0xeb, 0x8a, 0x2f, 0x44, 0xbd, 0x0d, 0x31, 0x98, 0xc5, 0xbd, 0x90,
-		0x99, 0x3b, 0x3e, 0x1d, 0x4f, 0x84, 0x01, 0x40, 0x04, 0x24, 0x81, 0x34, 0x85, 0x14, 0xe0, 0x1c,
-		0xe5, 0xfc, 0xa9, 0xf3, 0x91, 0x84, 0xb0, 0x12, 0x33, 0x31, 0xf6, 0xc7, 0x44, 0x53, 0x17, 0x30,
-		0x27, 0x0e, 0x51, 0x29, 0xf9, 0x55, 0x63, 0xbe, 0x9a, 0xa5, 0xce, 0x5f, 0x16, 0x59, 0xaa, 0x5d,
-		0x56, 0x82, 0xcf, 0xdc, 0x46, 0x8b, 0x25, 0x7c, 0x94, 0x81, 0x06, 0x0b, 0xbd, 0xbb, 0xb3, 0x15,
-		0x8b, 0x00, 0x2f, 0x81, 0xb6, 0x5f, 0xd3, 0x4a, 0x53, 0xe9, 0x45, 0x3e, 0x86, 0x83, 0xc3, 0x8b,
-		0xd0, 0xab, 0x37, 0xf5, 0x65, 0x5b, 0x61, 0x56, 0xe5, 0x12, 0x0b, 0x84, 0x9b, 0x2b, 0xfb, 0x0d,
-		0x9b, 0x6d, 0xd9, 0x04, 0x0c, 0x45, 0x43, 0xd8, 0xf1, 0x06, 0x82, 0x1b, 0x77, 0xf4, 0xd9, 0x1a,
-		0x43, 0x6b, 0x61, 0x2c, 0xa0, 0x40, 0xcf, 0xa1, 0x7b, 0xe8, 0x7f, 0xed, 0xaf, 0x97, 0x26, 0x1e,
-		0x07, 0x0c, 0xe1, 0xba, 0xe8, 0x5b, 0x92, 0x20, 0xcd, 0xab, 0x76, 0x0c, 0x92, 0x58, 0xae, 0x55,
-		0x42, 0xc2, 0x9f, 0x30, 0x85, 0xd5, 0x94, 0x7a, 0xdf, 0xc4, 0x88, 0x34, 0x72, 0xfc, 0x4b, 0xb3,
-		0x85, 0xcb, 0x88, 0xf5, 0x4d, 0xfc, 0x54, 0xc5, 0x59, 0x00, 0x8d, 0xb0, 0x8e, 0x3c, 0xae, 0xe7,
-		0xfe, 0x8d, 0x7c, 0xc2, 0xcc, 0x94, 0xa5, 0x4b, 0xdb, 0x0e, 0xfe, 0xfe, 0x73, 0x1b, 0xc7, 0xf0,
-		0x3b, 0x61, 0xed, 0xae, 0x60, 0x2f, 0xf0, 0x4c, 0x0b, 0x1a, 0x99, 0x8c, 0x9c, 0x89, 0xa4, 0x63,
-		0x9c, 0x33, 0x62, 0x44, 0x5e, 0xf4, 0x11, 0xc3, 0x1d, 0xab, 0x1c, 0xd7, 0x22, 0x58, 0x30, 0xa6,
-		0x62, 0x2c, 0x4e, 0xea, 0xa4, 0x32, 0x3c, 0x43, 0x45, 0xf0, 0xc3, 0x5b, 0x27, 0xda, 0x04, 0x96,
-		0x32, 0xdd, 0xdb, 0x99, 0x38, 0xeb, 0xe4, 0x9e, 0x6f, 0x1a, 0x9f, 0xc9, 0x40, 0xca, 0x09, 0xdf,
-		0x54, 0xed, 0xbb, 0x19, 0x6e, 0x78, 0xae, 0xa1, 0xf1, 0x6c, 0xbe, 0x8e, 0xa4, 0x67, 0x68, 0x7d,
-		0x69, 0xee, 0xcf, 0x6f, 0xdf, 0xef, 0x43, 0x9c, 0xb0, 0xd4, 0x77, 0x7d, 0x1c, 0xbe, 0xd8, 0x13,
-		0xf0, 0xba, 0x61, 0x34, 0x2d, 0xb8, 0xda, 0x66, 0x9a, 0xc3, 0xcf, 0x91, 0x6f, 0x54, 0x9b, 0xee,
-		0x89, 0x58, 0x41, 0x9f, 0xaa, 0xef, 0x4e, 0x66, 0x6c, 0x2c, 0xf8, 0x1a, 0xd7, 0x9c, 0xa6, 0x11,
-		0xec, 0x19, 0xd7, 0xd7, 0x89, 0x23, 0x63, 0x59, 0x62, 0x8c, 0x65, 0x3d, 0xc8, 0x9f, 0x83, 0xe1,
-		0xe8, 0x3c, 0x36, 0x1c, 0x9f, 0x9c, 0x76, 0x04, 0xdd, 0x42, 0x1c, 0x7a, 0x16, 0xb0, 0xf7, 0x83,
-		0x49, 0x3f, 0x57, 0x40, 0xe5, 0xb1, 0x37, 0x04, 0x4b, 0x8f, 0x10, 0xe6, 0x39, 0xe1, 0xc0, 0x43,
-		0x43, 0x17, 0x1a, 0xa2, 0x38, 0x2f, 0xcb, 0x6b, 0xf5, 0x25, 0xc8, 0xd2, 0x68, 0x10, 0x71, 0xf8,
-		0x01, 0xa8, 0xb3, 0x87, 0x60, 0x11, 0x34, 0x43, 0xf0, 0x57, 0xae, 0x8e, 0x8b, 0xf9, 0x5f, 0x9f,
-		0x3f, 0x5c, 0x2c, 0xc4, 0xfb, 0xeb, 0x0f, 0x73, 0x33, 0x7b, 0x9a, 0x21, 0x6a, 0x8d, 0x9e, 0x7a,
-		0x54, 0xd6, 0x0b, 0x7f, 0xb3, 0x4b, 0xde, 0x96, 0xe9, 0x43, 0xaa, 0x82, 0xac, 0x77, 0x61, 0x55,
-		0x3e, 0xbf, 0x70, 0x53, 0x72, 0x46, 0x58, 0xc8, 0xc2, 0x7e, 0x71, 0x8c, 0x5a, 0x20, 0xdc, 0xfd,
-		0xe8, 0x82, 0x5c, 0xeb, 0xe0, 0xb3, 0x49, 0xf3, 0x10, 0xb2, 0xb7, 0xb5, 0xd6, 0xc3, 0xb2, 0x6d,
-		0x4e, 0xfd, 0x61, 0x29, 0x84, 0xbd, 0xc7, 0x9a, 0x04, 0x6d, 0xc0, 0xb1, 0x71, 0x59, 0xca, 0xd5,
-		0xbd, 0x09, 0x13, 0x15, 0x53, 0x88, 0x32, 0xcf, 0x4a, 0xcc, 0xb8, 0xb3, 0x61, 0x0c, 0x6d, 0xb5,
-		0x71, 0xb3, 0x58, 0x0c, 0x71, 0x0e, 0xec, 0xb7, 0xb1, 0x6f, 0x3b, 0x61, 0x88, 0xf9, 0xbb, 0x2b,
-		0xef, 0x11, 0xc6, 0x23, 0xbb, 0x6e, 0xec, 0xef, 0x7b, 0x7a, 0x08, 0x67, 0x60, 0xf5, 0x8d, 0x83,
-		0xac, 0x1a, 0xb6, 0xf8, 0xbb, 0x1b, 0xb0, 0x61, 0x4b, 0x84, 0xfd, 0xc8, 0xd5, 0xaf, 0x7d, 0xe8,
-		0x33, 0xed, 0x0c, 0xb1, 0xb7, 0x02, 0x03, 0xd6, 0x5e, 0xfa, 0x86, 0xd7, 0x6e, 0x37, 0xc7, 0x44,
-		0xfd, 0x58, 0x29, 0x26, 0xdd, 0x52, 0x3c, 0x88, 0xf4, 0x53, 0xf3, 0xa3, 0xd5, 0xac, 0xdf, 0x8d,
-		0x02, 0x9c, 0xeb, 0x32, 0x84, 0x3d, 0x64, 0x37, 0xf6, 0x8e, 0x8a, 0x73, 0xd7, 0x30, 0x2e, 0x07,
-		0xfb, 0xdd, 0x4f, 0x38, 0xad, 0x01, 0x43, 0xbf, 0x09, 0x09, 0x21, 0xad, 0xf7, 0x99, 0xc4, 0x9a,
-		0x5d, 0x94, 0xb9, 0x2e, 0x76, 0xf9, 0xce, 0x7c, 0xb6, 0xd7, 0x33, 0x6c, 0x81, 0x75, 0xbf, 0xa7,
-		0xa9, 0xad, 0x70, 0xda, 0x84, 0x79, 0xb1, 0x23, 0xf8, 0x89, 0xb0, 0x38, 0xef, 0x6a, 0xec, 0xe9,
-		0x0a, 0x67, 0x7c, 0x62, 0x8d, 0x1b, 0x96, 0xa4, 0xbc, 0x56, 0x59, 0xd5, 0x4c, 0xd4, 0xc1, 0x48,
-		0xfe, 0xcc, 0x5f, 0x7a, 0xa8, 0x57, 0x3c, 0xa5, 0x3a, 0x4c, 0x6a, 0x22, 0x38, 0x08, 0xf1, 0xcf,
-		0x35, 0x10, 0xb0, 0xe5, 0x7c, 0xfe, 0xcf, 0xfd, 0x72, 0xfe, 0x69, 0x6a, 0x6b, 0x35, 0x13, 0xf5,
-		0x20, 0xed, 0x90, 0x5d, 0x7e, 0x5e, 0x4c, 0xf7, 0x94, 0x3f, 0x48, 0x37, 0xbf, 0xb9, 0xda, 0x83,
-		0x33, 0x9e, 0xe8, 0x74, 0xc0, 0x93, 0x86, 0x39, 0x92, 0x71, 0x00, 0x33, 0x72, 0xea, 0x8c, 0x06,
-		0x87, 0x8b, 0x59, 0x0d, 0x1a, 0xc8, 0xb7, 0x66, 0x13, 0xb1, 0x64, 0x9c, 0xd9, 0x65, 0x50, 0x9f,
-		0xd7, 0x69, 0xfc, 0x7f, 0x00, 0x00, 0x00, 0xff, 0xff, 0x32, 0x71, 0x97, 0xd4,
-	},
-}
+const data_fs_go = "x\x9c\xb4;ko\xdb8\xb6\x9f\xad_q\xea\x0f\xad\xd4Q\xe5\xcc\xde\xc1b\xe0\xac\x17\xe8$\xe9n/:M\x91\xa4;\xb8\x9b\x0d\x06\xb4D\xd9Dd\xd2\x97\xa4\x9a\xbai\xfe\xfb\xc59$%\xca\x8f4\xb3\xdb\xdb\x02\xad-\x91\x87\xe7\xc5\xf3\xf6\x9a\x95\xb7l\xc1\x81\xaf\xe6\xbc\xaaM\x92\x88\xd5Zi\x0bi2\x1a\x97j\xb5\xd6\xdc\x98I\xdd0\xcb\xc7\xf1\x93\xc5\x17\xb1\x1e<h\xbe\xdc\x0d\xbe\x7fi\xc4\xdc=\x90\x96\x09\xc9\xf5\xa4\x11\xc6\xe2\x13\xae\xb5\xd2\x06?\x09\xe5\xfe\x9d\xd4\xf4Ur;YZKp\x15=1V\x97J~\xf2\x1f\x85\x5c\xb8\xa7\x1bY\xe2\xffV\xac\x08\xa9V\x8aRU|\xd2\xda\xfag\xf7\xdd\xb0\x9a\x8f\x93,I&\x13@\x88\xc5\x1b\xd1\xf0\xcb\x8d\xb1|\x85\x8f\xecf\xcd\xa1\x7f\x04BZ\xaekVr\xb8\xc7\xd7\xa3\xf35\x97\xa9d+\x0e\xee\xd4\x0cR\x5c\x9d\x03\xa1\x9e\xe1\x9a\x87d2\x19@\x1f\xc0\xdd\x81x\xd2(\xc3\xd3\xcc\x01\xa0'\x97\x96\xd94\x83T\x19\xda\xfeV\xd6*\x86?\xba\xe0\xac\xaa\x84NK\xd5J\x8b\xf02H\xafo\x1e[\x9d^\xdf\xcc7\x96g\x90\x0ai\x07o/9\xbfMU]\x1bN\x90\xfe\xfcS\x0ewK.K\xee\xe1\xfag\xbb\xd4E\xc7\x0d\xe8Clwh|\xcfV<\xcd<\xcb\xc0\xfd\x99L`\xce\x0c\x07b\xa6\xaa\xc1.9\xd4\x9e[\xa3K\xf1\x057\xd0\xe9~=L&\xd0p\xb9\xb0K\x10\x12\x90\x1c\x03\xb5\xd2\xa0\xf9\xa2m\x98\xa6\xbd\xe6\x18\x0c\x89\xf2U\xc5\xd7\x5cV\x5cZZ\xa3\xec\x92kC\xa8\xfc\xaa*\x84\x8c\x8c\xc5\x8f\x012\xee\x86\x15>\x98\x0b\xdb\xad\xbc\x12\x847jS\x81\x9fq\xe5JU\xa2\x16%\xb3BIzC\x8b\xdf\x9aS\xa1\xd3\x0c\xe6J5\x11\xc2l>\xd7\xfc\x93p\x8b\x11\x13<3\xcd\x0a\xbf\x9c\xb6^n\x8c#\xd5\xb1\xec\xfe\xc1a\xd4\xca\x8a\xebf\x83\x0c\xab\x98e`T\xabK\x0ei\xc9$hn[-A\x8a\xc6\xcb\x04\xff=\x93\xa6\xd5\xdc\xc0Z\xab5\xd7 V\xeb\x86\xaf\xb8\xb4\xeepU\xf7G\x98\xe4\x13\xd3\xf0\xfb\xb6\xfe\xc3\x0c\xd2\x97\xbfWB\xff\x9d\xc9\xaa\xe1YJ\xf0\xb7\x96\xd2\x22\xe4\xd6\x9eE\xb5)P\xdbN\x85\xeeV\x1e\x00'\x14\xad\xe4\xfa\xb5}\x0c\xa0P\xc5oZX\xae\xaf\xd4c\xcb\x22]\xa4egh\xb0N\x85\xfe\xf6\xa27\xa2\x03\x95L\xbe\xd3\x1f\x94\xc5\x9b\xf3\x8b___\x91\x91\xf9\x07\xd7\xc6\x0b\x00U|\xc1%\xd7\xcc\xf2\x0a\xd00\x81]\x0a\x03\xba\x95\xa8H\xa09\xab\xcc\x94nB !\x873i\xf5\x06\x98\xac\x10X\x8744\xc8\x16\xd6\x18|C;\x10\x5ci\xfc1B\x93\xd2\x14\x00'K&\x17\xbc\x02%\x9b\x0d]m\x5c\xbcAX%\xbd\xc1\xcb\xc4\xe0\x8em@5\x15\xd7\x01\x17\x03%\x93/,\xa1T$\xa5\x92\xc6\xc2\x1b\xa5W\xcc\x06zf\xf0#\xd1\xf7\x81IQ\x1ahe\xc3\x8d\xd9&/\x10->q\xd2\xff\x15\xb3\xf0\xc9\x03@E\x9es:\x01\xe6\x84Q\xcc\x8c\x02\xe0\x8c\x95\xcb\x08\xe0\xd5\xf9\x09\x94\xaci\x0c\x08\x0b\x0c\xcd\x95\xb09\x18\x05v\xc9,0\xf0\xee\x0a\x01\xf5\x9b\xf0\xce1I\xf7?@\x86\x9a\x09\xe4\x9b\x05c\x99\xb6\xd0\xaeA3Z`\x97L\x82\x92P\x0bm,\xb4\x86\x17I\xdd\xca\x12.\xf8\xff\xb6BsG\x7f\x1a\xf0'\x03I\xf7\xfd>\x19\x89\xba\xa3\xeb\xd9l\x8bS\xf7\xc9h\xb4F.\xa5c\xefJ\xa7\x11\x86(7X2\xb3\xcd\x9e1\xfc\x80\x06\x13\x9d\x5c\xf1\xd6*\x16\xce\xcd\xe0\x87d4\x1a\x8da\xdeZbn \x0b\x19i\x0eo\x1f\xe0\x94\xc1\x0f0>F\xdb\xe9\xf1\x00a\xc7Y2zHF\xde\xb6X\xdd\xf2\xe4\xe1\xfb\xde\x8a\xd3\xb7\x17g'W\xe7\x17\xff\x83`\xe15\x99lP\x1a*\xa1yi\x95\xde\x902\xcaN\xf9A\xc8\x8a\x7fFM\xf8\xccJ\xdbl@IR)\xbc\xb5\xa4\xf7\xb4\x86\xac\xb5\xe1\xb6H\xc8\x09\xb9\xfbb\xacnK\x0b\xf7\xc9\xe8\x03\xb3\xcb\xe0ypa\xc3\xcc\xf2\x95\xe1kF\x0a\x92\x83\xe6\x0d\xb3\xe2\x13\x07\x8b\x9a\xc4#d\x96\xaa\xa9p\x17>%L\x92\x11\x9d\xdc\x9b\x8ed\x84\x18\xc0\xcb\x80\xb0c\x18\xbc\x96.f\xaax\xd5\x83C2\xa4\xd5\x82\x1b\xc0p\xc7\x00\xff\xc4\xf5\xc6\xf1\x80\xc9h!\xccy\xa3\xee@\xd8\x9c\x08S\x1a\xf5x\xbe\x815\xb3\xcb\x02\xe0\xaa7\x22J\x03_\x09k\x08\xef\xbb\xa5j8X\xcd90\x83\xaamEIF`\x0a\xa8\xffB.\x10\x1c^0\xbe\x10\xc6r\xcd\xabp\x8b\x88\x97~\x11\x08\x03\xab\xd6\x22o\x80\xd5\x96\xeb;\xa6+C\xf7\xacQ\xea\xb6]\x1b\x90\x9cW \x15\x82kTy+\xe4\xc2\xa3\xb5d\x06\x9ds\xc5?wV\x09IE\xa8\xde\x10tD\xbe0\xa0\xee$\x94K\xd1T\x9aK\x84\xc54\x87y+\x1a\x0bJ\x96<\xdf\xba\x88N\xb6A1z\xf1\x9e\x0a\x8d\xd1E\xf0\xb9^\xd2I\xf0\xde\x1f\xa5\xf8\xfc\x9eI\x85\xce\xef\xcf?%\xa3 \x82\xf0\xe7\xfa\x06\x9fl\x92d\x84\x87\xe2\x13\xb3\x91eq.K\x9e\x8c\x90\xe3\xb8t\xc5\xd6\xd7\x0e\xee\x8d\x90\x16\x9d\xf3Z\x19\x11\xdc*G3\x85KQ{=\xfcd\x14\x08\x1f\x04f\xe4\xd8\x03\xcdy$[\x0c\x82Py\xc8\xdc\xa4U\xafQ\x19\x0cc\xa7\xfb\xee\x82V\x85'=y\xd8\xb7m\x10A\xf5\xbb\x8e\xf6\xaf\xf6Q\x91G\x15\xbfE'\x1d\xfd\xf4\xd3O\xf0\x15\x1d(\xbepj\xbe\x1f\xc6N\xbc\xd4\x03A\xcb[\xa04\xd2\xa3\x1c\xaabK<\xd9~\x90\x83\xa8*\x82\xe5l\xd3\x9e\x0d\xbb\xb1T\xbfK\x8af\xff&\x8a\xe9\xb3A\xb0\x12\x22^\xe4BU\xa0j\x14\xa7*\xad\x0a\xd4\xcf\xea-\xda\x82\xac\x83\xfb\xbc\xdf\x86\x96\x1eo\xde\x14\x15\x09\xa0\xca\x93\xd1\xa8\x12\xda\x85:S\xe8>\xde\xe3\xad7S\xa8\x0a\xaf&\x0fy2z\xc8=\x8a\xfbp\xec\x0fN=R\xa8q\x06f\xb0b\xb7<\x1djh\x8e\x81rZ\x15^\x19\xb3,\x19u'\x85\x1d[\x09\xc3Q\x96\x8c\xd0W\x0a\x98\xce@S`\xd0\xed\xc7\xf3F\x1c_<\xef\x9e]\x8b\x1b\xa4\xcdaq\xcd\x8b\x0f\xcc.o`\x06\x22\x19\xa1/\xf4)YA\xac\xfaecy\xea\x96\xe4\xf0b\xf2\x22\x83\xbf\xce\xe0\x88\xa0\x8e0\x07\x14\xb2\xe5\xc9\x08}\xcf\x08C\x1c\x7f(\x9a\x83\xfe\x82\xe09xa:\x8b\x01\xa5\xc2\xbc\xa1\xb5[\xb7\xa8p\x08p\xbc\x1d\xf0l\x86,u'\xc5\x1c`k\xcc\x0e\xd2\xeeQ\xee\xd6g\x88\x05\xf0\xc6\xf0\xa7mA\x17@{\xd0q:\xd3\xffF\xc8\xca\xd9c\x8ev\x05\x9d:\xb0m\xa7C\xa6|\xcb\xf1\x08\x13\xb9\x8a}\x1a\xe0\xac\xefV\xee\xf9\x12\xc5\xb1\xc9)\xedx\x5cYE\x0d\x22\x07\xfe\x99\x5c\xcft\x06At\x08\xef\xe68\xbc@\xb2\x83Z\xc7\xb2\xce]<\x10\xc5\x07R49\xd4\xac1>J\x80\x0b\xdaf\x80A\xa9\xd6\x9b`\xf0Q\xbe\x5c\xda\x10\x97\x92\x88*rz{\x89\xc4\xeb\x81\x5c\xdd\x22\xd3\xa5\xaf\x83K\xc9,\xa3\xef\xa8\x96U\x81*fh\x93#\x95\xeb\x81\xf0c\x9c\xb9\xd6q\x9c\xe3\xe5\xeaN\xa0|!'\xafY\x14E\xd6]\xc8\x88\xbc\xc7\x89\x82;a\x97\xaa\xb5\xc4\x03!\x17\xc7`8\x1f\x04\xee\x0e\xd5\xbd\xc4\xf7D<Fy\xd9p&;\xd2\xe9\x1b\xde\xact\x8c\xf1\xdf8\x87?\xc4\x04R\xd2\xa1Zx=#\xc0\x8e\x99\xcf\xfc\xeb\xaf_\x81\xd6\xfb<p?\xe4\xe7\xca\xd0M?\xc3\x8a\xc1\xfd\xf9z\x0a\x011|:%\xf4r8\xd3z\x8a\x0e\xe5L\xeb\xf7\xca\x9e!\xfc\x87X*\xfd9^\xb4\xd9\x8e\x96\x11\xbb]\xb6\x0fZ)4\x01\xccF\xe2\xd8\x8e\xa7(\xb9P\x92\xefe\xfde;O+\xa1{\xbeo\xa5\xc6O\x12\x80i\xe7\xe3\x1c*\xa1\x9f\xcc~Q\xbb\xfd0\x9b\xc1\xb8\x18\xc7\xeb\xaa\xc2\xb9\xa5\xff@Lh\x01\x9f.%\x87=>$G\xf5T\x19\x9d\x0a\x1d0u\x12\xba\xf2\xf2A)\xf8\xc4\xcf*\xc0%\x18\x8c\xb8\x18\x1bS\x1d\xcc\xaf*\x0c\x99\xba\xf0z\x0a,\x99L\x9eb,\xa3@2\xc7h\x97\x95%_\xe3Z\x97G\xd6\xa6\xf8\x07kD\x85\xc4`=\x8bY\x8c\xb5i\xdfR\x19\x0b\xe7\x97>`\xd5m\xe3=\x0d\xbe3\x881\x1al\x5cM\x80\x88DX+!\xed\xd4\xd7\xbd\x00^\xa1A\x17r\xd1ph8\xa3\x0c\x81P\xc6\xc8\xb9\xd2j\xbd\xe6\x15y\xaa\xf1\x84M\xe6c\x8a\x84\xc7\xeeSt\xceq\x80\xc5Wk\xbbq\x8b\x8a1\x18\xbe\xc0\x92\x8d\xc3i\x00\x8cM&\xc5d>\x19\xe3)\x04\xae\x830.\x8a1h\xbeR\x9f\xb8\xf3<\x1e\x08\xccy\xad4\xc7D\x82rE\x89dA\xd9\x88\xd5\xdc\x00\x9b+Ly\x90\x07J\x11q\x18\xb0\xc0\x98M\x8a\x02q\xc5S\xe6c\xb8[\xe2-\x1b\x17EDIQ\xf85\x88cM\xfa\xf1V~B~w(\xcdYyK<A\xee\xca\x0a\xde\x7f|\xe7+v;{\x06\xb9\xb7\x17\xba\xd2\x06\x5c-\x14q\xb2\x5c\xaf\x84\xc4l\xc7\xa5!^\xb5\x5c}b\x85\xb7\xa7\x12u\xcd5\x92l\x97X\x09\x06\x15?C\x91\x1b\x8f\x1a \x00\x81Hmh\xa9\xab\x1d\x08\x83\xa0>^\xbdy\xf5s\xe1\xc5\x8cY\x96\x13\x0d\xa9_Hg\xd0\xd0\xf4\x91\x073(\xb4\x02(F7\xbe\x0e\xa19\xb0\x06M\x1ef\xb3]U\x834\x1e\x89w\x97\x07\x8d\x15n\xe0\x1b\xdc\x90wn\x835\x8d\xc2\xd2\xa2\x5cx3\xd5\x9b\x17\xb5v\xb6\xbd\xb7Q\xee\xc3\x8eiB\xfb\x80\x0b\xc9\x08a HO3\xf8+\x1c\xc1\xf3\xe7\xce\xe0\x5c\x1f\xdd\xa0\xcdy1y\x81\xdb\xfc>o\xcc\xae\x7f\x9c\xde\xecZ\xa7\x81q\x1a\x17\xe8hD\x13\x96\xed\x86{\xdeH\xbe\xf8\xd7\xbfB\xb8\xf7\xf5\xeb\xe1eGQH\x18\x8e\x18\xef\xb3Rj\xbd\xc7\x8f\x0c\xf4\xe9!\xe0\x14\x1b\x81\xc0\x82\x08\xbe?9\x22\xe2\x196\x09\xdc\x9eKBt\xcf\xae\xff\x08\xabd\xd4]\xefi\x17\x85\x07!\x1e\xe5\x1d{NT+m`\xcdx2\xce~\xf8\xd1G\xe7\xbf\xe7\xdd\xe5\xee\xc2\xf4\xb0\xebr\xdd\x88\xc1.\xe2\xa6\xb9\x13\xb6\x5cv\xbb\xf0Q\x89Uw$d\x5c\x8c\xa7\xdd\xf7\xc2}\x09:\xe37\x98\x0cf]\xac\xfe\x1d\x98@\xb1\xf2\xa8\xe7\xc3,`f\xae\xa7\x83c_\xfdH\xf9\x05\xafY\xdb\xd8\xe9\xd6\x1e\x1f\xb3\x85'\x1dS\xbaX\xfc\x11*\xf6i\xb0\x7f\x168\xf9\xdfJ\xf4[\x9d\x04\xbaH\x90\xca\x10}\xc2\x17\x15\x220\xef\xebC\x8a\xa4\xcf\xfb\xba\xc8\x05\xcbs,\xa4+!\xb3\xb0K\xad\xda\xc5\x12X\x14\xb0,}\x0e\x8a\x16\x87*\xb3.-\x87Ja\xe4H(t\xd0#\x0c\xfa6N2\xc2\xe8\xc8\xc0V\xf5\x01\xbb\x17\x0c\xe3!0K\x86\xf5\x9f9\xb7w\x9cK\x7f\xa09\xf6>\xc2\xb58x5\xc0|\xe0u\xa1\x11\xb7<\xe0U\xf8\x86\xd4\x94\xcc\x18\xb8\xb6\xd4_\xe1(\xf7\xc6\xce`\x89\xd5R\xbd\xc8\xbd\xe3>\xc5\xabU\xd3\xa8;\xc7\x05ez\xbb\x89\xff\xe6\xe48\x84*\xce\xce\xdfPA\x08\xad%\xdaVM\xf1\xb4\xc4R`\xc3k{\x0cT\xdc\xbd\x13&\xd8V\x03\xaci\xd0!2!\x11v8\x0d\xe11\x94\xa33\x98Er0\xd9D\x05.\x00.\xb8i\x1b\xef\x8bK\xb5\x16\xe4\xd7\x05\xa2\x82eh\xae\x0d\x98\xb6\x5c\x023\x83n\xdf%\xd7\xc8C\x143\xa2\xbc\xc2\x10g\xdd\xb0\x92\xef\xd4\xc4\x84\xe9\xe4\xb0\xe9c\x0e'\x8b(J\xedD\x9d\xc1\x93;\x7f\xa8\xea=\x07(E\x22\x8d\xb8\xae\x0a\xa7%h\xe2E\xede\xf5\x97p9\xc2[\x98\xf9\x0a\x02m\xca\x92\xd1N\xb6\x14\x9dy\xff\x90\xf7\xdc\xee\xd3\xa6\xe8\x1avow\xef\xe1\x0e$'\xf1\xb0;\xe8\xd2\x16\x14\xc4\xd5\xbd\x9am\xbd\xa2\x8d\x1d\x19?\xcc\x1c\x85\xc9c\xf8\xfb\xa4\xaf\x83q=\xa5=7\xbb\x19\xe0\x13\xae\x01\xb5lP\xaa\xa1p\xdcU\x0a\xf1n\x040\x95\xd0\x87\x05|\xba#\xe0\xda`u\xc2\xa7\xfa\xbd\x80\x85\xac\x95\xe9\x12\x91*\x5cC\xa7\x1d\x99K\xee\x04\x8f\x9d\xcd\x00\x102\x8e@d\xa1\xf6\x93\x03~\xef+@\xf8\xcd\xd5\x04<\xa8kq\x033\xa8{\xde]\xa9\x00/\xc5\xc5\x83\x0e\x82\xdfC\x08\x0e\xaaZ[\xf4\xa6k\xd8\xd7\x9e\x8e\x0b\x90\xfe\x99)\xde\xf3\xbbt,\x95\xa5\x0a\xc28\xa4\x1c\xe7\xd8\xdb\xd2\xfcN\xc8*\xdc\xabv\x8d\xa3\x0axc\xef\x96\xa2\x5c\x82\xe6\xd4\xec1\x81\xfdPk\xb5\xf2=g\xac1#\xb2\x9bb/\x8e\x7f\xa8E\x8eX\x8b\x1a\xfc\xf2g>\xf0\xf1\xeb\x9f\xcd@\xa8\x02\xe1]\x222\xf1=8\xcaA\xc5\xber\xa8\xc738\x8a\x99\xe1u\xb2C\xb6\xf7G\xbe\x989\x8c\x13\x9dE\xe9-\x14q3\xc2\xf7`B\xab\xd6\x5c~\xbb\xa2\xd0\xdd\xd5\xedL\x16;! \xf9\x9d7i}\xafN\xfb\xb4ix\x97\x82'\x14\x96\x1c\x9d|a]A\x10\x13\x9b.{\x07\xd6(\x0c\x8e;\xc6U\x05:\xde(Y\xde\x9b-\xd3\x9a\xc3)3b\x8b<\x99\xed\x89k\x02\x17\xbe]\xbe\xd8\xe2G\x9f\x84\xefV\x01vrgB\xdc\xd7\x95:QDU\x10\xff*\xfd\x96\x1c\x96(\xc2\xe7}\x9f\xbc\xafH\xf7\xc0\xb0*\x8dDM\xa9\xa8\x87\x95\xe7d\xb4,P(\x5c\x17\x17\xdcp\x9b\xfa\xe3\xb2d\xe4\x95g\x06\xcb\xa0\x81\x07Uo0\xc9r\xb8\xf0\x1eoyd\xd4%\x02\xe0d\x1c\xd9\xe2\xef\xf3\x17M\xc7\xc5\xd9\xdf>\xbe{}\x01o\xde\xbe;\xa3`\xe7\xc4O)as\xc7\xb7\xd45/\x95\xc6^\x9e\xf0\xadIdbq\x82/Ck<MF\xf4\xfd=\xaa(\x00\xcc`<\xf6\x8f\xfe\xd9\x88\xb9\x7f\xe4\x07\x9f\xe8\xf1)\xa7\xc9)\x5cY\xb9\x8fcl\x0eiv\x07\xfe{\x0eR\xc1\x92\xa4\xe2\xf7\xfc\xed\x8bX{P~\xc8\x8a\x1e\xbf\xfb\xe7o\xe0\x1f\xe3\xa8\x15\x82yw\xf9\x0b \xce:\x87\x9f_\xcd\x85\xed\xc6\x05\x92l\xa7C\x89\xcaR\x00\x9c\xe2\x90\x09\xa6\xc6\xdept\xb7u{jA\x1a\xcb0\x0e7\x96Q\x91\xc4\x97pP\x7f^\xd1\x94\x01\x96OCit.$\xd3\x9bA\x92?\xe7\x08\xdd\x05T\xa1\x9c\xb3\xe4l\x1d\xf7\xf8\xde\x88Al\x8d_\xe3._h\xf2\x9dk\xb1\x10\x925\xdb\xcf\x83\x10yE\x8f\xa9<\xee\x99\x15\x96F\xab\x89\xf2=\xcf\x03tl\xa5\xe1\x0b\xdfB\xdcj]\xf9\xc7!7\xe8\xaf^\x84?\xaa/\x84>1\x12\x93\xd0\x05\xec0\xd3](O\x09\xa0\x8f\xec'\x13P\x18J\x22~9\xf6\xc9\x91S%+\x97\xd8V\xe6a\x9c\x8eF\x08\xe8\xb6\x9a\xfef\xd6\xd1Y\x87[\x88u\x11\x18{h\xe7\x81.b]\xc4\xbc9\xb4\xf9\x9bM\xc5G6>\xb5\x93X\xef\xeb$\xee\x05z\xa0\x97\xd8\xb50\xf6\xed\xf9v;\xf1`k`\xab\x11P\x00|\x94\x91\xc8PM\x8cOX\xe8\xea! a\x0do\xea\x10\xb24\x02\x0bzBF7k\xc5WJo\x8e\xe1\x10 \xbb\xa4\xe4\xe81\x1d\xc11\x04\x81\x19\x13\xcd\xfc \xca\xa6\x11%\x87Uk,\x0e\x0a`v\x18\xf2\xbfb/S|A~oob\xcb\x81\xd5O\xf1[\xc3\x92\xb8\x7f\xe6\x065\xddQ\x1eF\x96\x1f\xe09\xd9\x1b\x9ayP\x1a#\xbe\x88j\x94\x82\xb7N\xbe\xecF6\xbd+\xb8\x11\xb7\x5c\xab\xa6\x0fO\x84\x0d\xb3IkFP\x94\x04cE\xd3D\x90}\x01y\xc8;\x84\xf6M\xf6]\x12\x96\xe8\xf4\x08\x15\x7f+]\xd3\xa9\x0b\x84#\xfd,hY\x9a\xe5\x03\x9e\xd4\x05*M\x08\x80Q0\xf1\xec\x95\xaf\xfcz\xffu\xc75\xef\x9d\x18\x99h\x0c*{#\xe9rm\x9fY\xa3\x93\xda\x8f\xb9G$\xb2#Xfs\x8e\x10\xe3\xbe\xde\x03>\x7fN\xcf\x03\xfcX\xd8\x9dO\x8c\xa5\xed\x81\xec\x93\xed>\x1d\xce\xfb\xe8\x9dT\x9d\xe2qX+c\xc4\xbc\xe1\x87\x90\xf7\x8a\xb8\xafn*\xea\x9e\xcfCJ\x22\xcc\x1d\xcb\x07\xc9\xad\x07\x9a\x07\xab<\x9du8\x16\x0bn\xd3:;\x0e\xaf\x22@\xdd\xae\x00i\xd0\xcc\xac\x0b!\xc9\xff?zg\xc6\xe3\xfe\xcaL&\xf0\xde\x0f\xef\xdc\xe1\xb4\xa4A\xaf\x8a0\x1d\x9b\xa8l\xa2$\xe9wPmV\xdev\x8e\xbe\xe8\xee-\xc6\x8eN\xc9|\x01\x14\x81d\xddkS\xac[\x9b\xd6y\xa0\xb1\x1f\x81\xf0\x0f\xe2\x0bz\xda\x0b\xce\x90\x81\xeb\xef\x22\x9a\xac\x17&\xdc\xc4\xd6\xa021X+\xd5\x0c\x8c\x96\xd2\xfb%\xd9\xb1g\xbf\x05\xc2;\xe5M\x0f\xa9k2B\xc89\xdcJ\x1c8\x9a\xce\xc2~m\xaei\xb1+\x84<s\xaf\xf7X\xa5.\xedl\xa5[C\xbb\xa64`G\x1f}\xcd\x81\x97\x9d\x08\xf1\xc0BS M\x1f\xff\xc6-\xde\xdf\xe0\xde\xdf\xf3;\xe7\xe1\xc3-~\xb2qDyD\xe9\xbc#~\xe8\x8c\xb3d\xf4\xbbCd\x16\xa6l\xdf\xb4M\x93\x92\xd5\xf3\xf2\xf4\x87E\xbdA_\x97@I\xe0T\xb3\xf2\x13\x0c\x15\x94K^\xde\xfa\xb6\x92\xd5\x9c\xad^\x18\xb0\x9a\x89\x86\xeb\x1cp\xf4\xcc-hW\x0e\x08\xda\x117\xa4\x9d\xfbr\x17\x8e\x98a\x0f\xa6\xa9\x0c\xc6\xb3+\xecHQ\x18\x18#\x8d\xf9\x1c\x8e\xf2\xf2\xcfV3\xb8\xfe\x91\xac`_\xbd>L\x10m\xb8\x9e\xded\xc7\xb4\xa2+o\xbb*\x12\xd5\x8d\xf19\x11\x1a\xdeI\xd1D/b\x09\xf7\xc8\xc5\xd3\x93np\xf2\xad\xb4\xe9\x90\xd39\xfcxDs\x94\xbe\xb5\xa5\xea^'\xe81r{\xdc\xd7\xa4I\x15>\xb4\x161\xff\xa6\xc4\xf7\xe4\xa1^o\xbbT\xb4\x8f\xde|B\xca\xb5\x1e4h\xab\xce\x5c\x852\x09\x96.1I\xabr\x92\xa8\xcf\xed\xdc<,\x95\xb2\x98\x7f\x0f/}\xd4\x96\xbbk\xebj\x13'\xf4.\xdc\xca%\xc4\xf3\xd8\xbb\xd9\x1f\xb6T\x96\x05\xc5\xb9\x11u\xcb\xc2\x9d\x90\x8e\xe9\x7f?t\xea\xd7\xcd\xfcDIX\xecq\xdf{\x9e\x07\xa3\xd6\xfe^E'\xfb\xdd\x8f\xf5(\x96\x94Rns0&\xf3\xe1\xe0\xc9OKZ\x97\xc3\xa4u/\xa4A\xc5\xae+\xf7=\x0ew\xc7.a\xd0\x16u\x0f\xc6\xd9\xa3\xc7\xa5\xf3\xb6\xae\x0f\x16\xdc\xf6\xcb\xec(\x8f\xc4\x86\xd1\xa8\x97\x9a\x7f\x1d\xd5\x0e<\xfc\xc7Qxmc$\xf2P,\xa3<\xea\xff\x03%\x7f^8(\x04M\xbfy_\xb9\xe4T\x1d\x0c\x19\xeb`\x8c\x87\xd1\x987\xd7+^\x09\xcc\xd6\x11o\xae\x8f\x9d\x9f\xd5h\x17\x99EX\xdd\x8f-\xd0D9\xe7I\xe05,\xb8\xdd\x9f\x180\xb9\xe9\x92\x83\xfd\xbc\x22\x08W*\xbd\xeb\x7f\x07q\xa0\xdc\xf8\x1f0\xa8;\xe4\xb0\xcc\xfe\x9d\x02\xe8\xb702\x9c\xdf\x1e\xc0(:.\x1c\xe4E\xf6}\xfe\xa2\xbcN\xcfN\xce\x7f\xfdpqvyyv\x0a'\xe7\xef\xaf\xce\xde_\xc1\xc9\xeb\x93\xbf\x9f\xf9\xe0\x85\xfa\x8e>\xdf\x82y[-\xb8\xcd\xbb\xdf\x1d\xe54\xd6\xb774\x0d\x05!\x0f\xe1\x04\x83\xbfwb%\xb0\x86\xfb_\x7f\x82\xbf\xfc\x05\xfet\x94\xd0/R:\x85\x98\xc1s\xff\x99V\xdf\xe3\xec\xf0J\xd0\x0c\xe9\x0e\x94\xbc\xab\xeaO\xfb\xc1\xcf>8\xbay\x89m\xc5\xe2\xcc\xfd\xf0'\xcb\x93Q\xa3[\x04\x04\xd4t\x22/\x97\xe5^\xff/\xb9\x9f\x1c\xff\xe3T\xc2-_\xdb\xd0=D\x86\xe1\xf54x\x83\xa2\xc5\xa8B\xd8\x9e\xf8$J\xdf\xe9\xc4\x8e\x1d3\x16s\x11.q\x9e\xbf\x1d\x00u\x8d4\xc9y\xc5\xab\x02\xe0\x08*a\xd8\xbc\xf1w\x94\xe2h\xef{.y\xc4\x93\x94\xd8\x15\xccG\x9f\x85\x9a\xe2\x9d*o\xd38~m\xbc$\xe8\xff\xe89\xe18X\xf9Q\xe2h{7\xd4\xf4Z\xc2\xbb\x8b\x8f>\xdbP\xf5\x01\xae\xcc\x15\xfeh\x8b\xday\xee78VY\xd6\x80\x11_\xc2\x00\xbb_J\xc8\xc7\xf5!\x9c9\xff\xb5\xb5\xfc\xb3\x17~_m2\xc3\xe2\x93\x97><&w\x92:\xee\x01\xa7\x0e\xef\x84\xa1\xa1uU\xc3K\xa2\xa0\xcaa\xa5v\xe4@\xc3\xf6]\x8f\xdb\xa7-=\x8e(N\xd8*byr\xbc\x07\xee\x0dH\x09/cJ3\xb4\x82[Q|\x97\x85u\xa3\xabe'\xaf\x8a\xd78\xa9\xd4\x0b\x01-\x0a\x8f+\xfae\xe1\x19q]\x0f\x87W\xcb\xa2\xd1m\xf1\xab\xfa\xc4\xaf\xd4\x1b\xad\xa4My\xd4\xbe\xe48\xde\xd1\xf2\x22\xf5|\xc8B}bw\xbeu<\x1e\x8e\xb7\x9etB\x16\x8bE(e\xf6\xbf\xbbp&\x82Z\xc5\xe8\x8f\xf1~\x14\x87\x98\xb1n\x87\xcc\xc8a\xc8\xc7\xa7p\xe3\xf7or\xe3\xebW\xa7B)\xf6\xf9\xfc\x01\x19N\x01\x95\x85S\xb1\xde4\x13\xdd1\x10@\x0e##?\xb4f\xe9\xd8\xf8\xdcq\x8c&\xd7\xa7\xd0'\x81\xd3\xf0\xe1\x01\xafOA\xea\xfa\xc3l\xdf\xd1\xf8:\x5c\xb4\xc3\x9a\xe2W \x0b\xd0\xc8z\x88C\xaci\x16\xdd!\xf8\x0bs\x0aBfq\xd3?\xbf\xe0\xd86Jy\xd6\xcb\x9a\xe6I\x1a\x8eSG\x81\xd4\xdcwEj?\xc8\xedO{\x15\xe3\xefVDT\x84Q\xef\x0fJ5fh\x0chD\xed\x96op\xb4\x02\xbd;v\x08\x00\xa3\x0b\xee\x9b^\xdct\x99.\x82\x88wR\x85\x97Q\xbf\x0c\x0b\x0f\xe1\xa7z\xb4\x1a[\x9a4\x8d\x96cn\x86\x90\x5cj\xaf\xbdY\x09)-\xa2\xb4mV>\xf8\x0a3zoTHL?\xe2\x92f\x0e:\xe4U.\xbe\x08\x9f\x83GGr\xd1a\x85Cp\x02'\xfa\xbd\xc1\xcb\xf8\xf0{_e\xc7\xa6\xc7\x14\xee\xe9\xd4\xe9\xbfw,j\x00j\xfa\x97\x1c\xd4-\x0a\x16\x99\x99Ru\x8azT\x96\xeb\xec\x18_\xe1\xbapkiM\x0f6\x87/\xbe\xa1\xa5)\x10\xf7\xc9XXM\xc0\xfal\x1c\xe7v\x1f\x1e\xf2a\x83\xe6;QQ\x0f\xa8 \x86\xfd\x112\xea\xc7\xc8p\xd0b:Bi\xa9#\x06;G\xdf\x89\x92\xc5P /\xb1\x13\x15\xb6\xed\x10\x82\x8b\x17\x9d\x0c\x02\xde\x8b/]\xa9\x84v\x0fE\xb0'3\xde\xce}\x86\xf4/\xbe\xec\xd0\xfb\xee\x9f\xbf}'r\x9b!\xb5\xcd\x97\xbb]b\x9b^:\xf8\xfe\xdd\xe5/9\xfc\x9cEx7\x1e\xc1\x18k\x5c\x19\x11>\xd8\x1a\xd1\xd3y\x1f\xff\xc3N\xd3\x0dm\x89\x15\xfeNo\xdep\x0aJ])\xdaM9\xf9BS\xbe\xd3\x84pFwP\xe2\x9bw\xb9\xe0N{\xc8\xad\xf3\xe9L\x1a\xbe\xe11X\xcfK\xe7\x99\xfb\x9d\xd1<\xeb\xedy\x5c\xa26\x9d;s'\xec\x81\x8c\xb0:\xc0t\x0cA6\x1e\xb2\xc9\xb2\xe4!\xf9\xbf\x01\x00y\xc0f^"
 
-func init() {
-	DIR.AddFile(&fs_go)
+var File_fs_go = embedfs.EmbedFile{
+	FileName:        "fs.go",
+	Original:        "embedfs/fs.go",
+	Compressed:      true,
+	Codec:           "zlib",
+	ModTimeUnixNano: 1792396710318615581,
+	OriginalSize:    17031,
+	Data:            data_fs_go,
 }
@@ -8,17 +8,23 @@ import (
 	embedfs "github.com/gyokuro/embedfs/resources"
 )
 
-func init() {
-
-	DIR.AddDir(DIR)
+// Fails at init if the runtime can't read this package.
+var _ = embedfs.RequireFormat(1)
 
+// Index of every file and directory below this one, sorted by path.
+var DIR = embedfs.EmbedDir{
+	DirName:         "embedfs",
+	ModTimeUnixNano: 1792396710318615581,
+	Entries: []embedfs.Entry{
+		{Path: "fs.go", File: &File_fs_go},
+	},
 }
 
-var DIR = embedfs.DirAlloc("embedfs")
-
+// Returns the directory at the given path below this one, or nil if there
+// is none.
 func Dir(path string) http.FileSystem {
-	if handle, err := DIR.Open(); err == nil {
-		return handle
+	if fs, err := DIR.Sub(path); err == nil {
+		return fs
 	}
 	return nil
 }
@@ -28,5 +34,50 @@ func Mount() http.FileSystem {
 }
 
 func FileInfo() os.FileInfo {
-	return DIR
+	return &DIR
+}
+
+// Returns a copy of the contents of the named file.
+func ReadFile(name string) ([]byte, error) {
+	return DIR.ReadFile(name)
+}
+
+// Returns the contents of the named file without copying.  The slice must
+// not be modified.
+func Bytes(name string) ([]byte, error) {
+	return DIR.Bytes(name)
+}
+
+// Paths of the files in this directory; those below have their own in the
+// packages of their directories.
+const (
+	PathFsGo = "fs.go"
+)
+
+// Returns the contents of fs.go, not to be modified.
+func FsGo() []byte {
+	return mustBytes(PathFsGo)
+}
+
+// Opens fs.go.
+func OpenFsGo() http.File {
+	return mustOpen(PathFsGo)
+}
+
+// The files named by the constants are embedded; failing to read one is a
+// bug.
+func mustBytes(name string) []byte {
+	data, err := DIR.Bytes(name)
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
+
+func mustOpen(name string) http.File {
+	f, err := Mount().Open(name)
+	if err != nil {
+		panic(err)
+	}
+	return f
 }